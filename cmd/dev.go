@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/fakeserver"
+	"github.com/spf13/cobra"
+)
+
+// devCmd groups development helpers that aren't part of the regular workflow
+var devCmd = &cobra.Command{
+	Use:    "dev",
+	Short:  "Development helpers",
+	Hidden: true,
+}
+
+// fakeServerCmd represents the dev fake-server command
+var fakeServerCmd = &cobra.Command{
+	Use:   "fake-server",
+	Short: "Run a fake Mastodon API server backed by fixture data",
+	Long: `Serve a fake Mastodon API from fixture JSON so templates can be developed
+offline. Point mastodon.server at the printed URL; any access token is accepted.
+
+Uses the built-in fixtures unless --fixtures names a directory containing
+account.json, statuses.json, favourites.json, and optionally histories.json
+and contexts.json.
+
+Example:
+  mastodon-to-markdown dev fake-server --addr 127.0.0.1:3000
+  mastodon-to-markdown fetch --start 2025-11-03 --end 2025-11-10 --config fake.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		addr, _ := cmd.Flags().GetString("addr")
+		fixturesDir, _ := cmd.Flags().GetString("fixtures")
+		pageSize, _ := cmd.Flags().GetInt("page-size")
+
+		fsys := fakeserver.DefaultFixtures()
+		if fixturesDir != "" {
+			fsys = os.DirFS(fixturesDir)
+		}
+
+		fake, err := fakeserver.New(fsys)
+		if err != nil {
+			return fmt.Errorf("failed to load fixtures: %w", err)
+		}
+		fake.PageSize = pageSize
+
+		server := &http.Server{
+			Addr:              addr,
+			Handler:           logRequests(fake),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Fake Mastodon server listening on http://%s\n", addr)
+		fmt.Printf("Use it with:\n  mastodon:\n    server: \"http://%s\"\n    access_token: \"fake\"\n", addr)
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("fake server failed: %w", err)
+		}
+
+		log.Info("Fake server stopped")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+	devCmd.AddCommand(fakeServerCmd)
+
+	fakeServerCmd.Flags().String("addr", "127.0.0.1:3000", "Address to listen on")
+	fakeServerCmd.Flags().String("fixtures", "", "Directory of fixture JSON files (default: built-in fixtures)")
+	fakeServerCmd.Flags().Int("page-size", fakeserver.DefaultPageSize, "Maximum items per page")
+}
+
+// logRequests logs each request at info level
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetLogger().Infof("%s %s", r.Method, r.URL.RequestURI())
+		next.ServeHTTP(w, r)
+	})
+}
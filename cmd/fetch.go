@@ -1,218 +1,171 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"sort"
+	"io"
+	"os"
 	"strings"
+	"time"
 
-	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+	"github.com/lmorchard/mastodon-to-markdown/internal/epub"
+	"github.com/lmorchard/mastodon-to-markdown/internal/media"
+	"github.com/lmorchard/mastodon-to-markdown/internal/merge"
+	"github.com/lmorchard/mastodon-to-markdown/internal/postcsv"
 	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
 	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
-	mastodonAPI "github.com/mattn/go-mastodon"
+	"github.com/lmorchard/mastodon-to-markdown/internal/wxr"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// fetchNow returns the current time, for output that records it; tests replace it for stable output
+var fetchNow = time.Now
+
 // fetchCmd represents the fetch command
 var fetchCmd = &cobra.Command{
 	Use:   "fetch",
 	Short: "Fetch posts from Mastodon and export to markdown",
 	Long: `Fetch posts from your Mastodon account for a specified time period
 and export them to a markdown document suitable as a blog post starting point.
+Use --account to export another account's public posts, --hashtag to export
+a hashtag timeline, e.g. for an event round-up, or --source to export a digest
+of your home timeline or one of your lists.
+
+With --watch, fetch keeps running and polls every --interval, appending only
+posts it hasn't written before to the output. Written post IDs are kept in a
+state file, so restarting doesn't repeat anything. Stop it with Ctrl-C or SIGTERM.
+
+With --merge, fetch refreshes an existing Markdown draft instead of overwriting
+it. Each post is written between status ID markers; posts you haven't edited are
+updated, posts you have edited are kept, new posts are inserted next to their
+neighbours, and text outside posts, such as your commentary, stays where it is.
+Posts that are no longer fetched are kept unless --prune is given. Merging only
+works with the markdown format.
 
 Example usage:
   mastodon-to-markdown fetch --since 7d --output posts.md
   mastodon-to-markdown fetch --start 2025-11-01 --end 2025-11-07
-  mastodon-to-markdown fetch --since 24h --exclude-replies`,
+  mastodon-to-markdown fetch --since 24h --exclude-replies
+  mastodon-to-markdown fetch --since 7d --link-roundup
+  mastodon-to-markdown fetch --since 7d --account @someone@example.social
+  mastodon-to-markdown fetch --since 7d --hashtag gomeetup
+  mastodon-to-markdown fetch --since 7d --source "list:Go" --min-engagement 10
+  mastodon-to-markdown fetch --since 30d --include-pinned --template about
+  mastodon-to-markdown fetch --since 30d --format csv --columns id,created_at,favourites --output posts.csv
+  mastodon-to-markdown fetch --start 2025-11-03 --end 2025-11-10 --merge --output draft.md
+  mastodon-to-markdown fetch --since 24h --watch --interval 10m --output journal.md`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindPipelineFlags(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log := GetLogger()
 		cfg := GetConfig()
 
 		log.Info("Running fetch command")
 
-		// Load Mastodon config from viper
-		cfg.Mastodon.Server = viper.GetString("mastodon.server")
-		cfg.Mastodon.AccessToken = viper.GetString("mastodon.access_token")
+		// Load template config from viper
 		cfg.Output.Template = viper.GetString("output.template")
 
-		// Parse time range
-		since := viper.GetString("fetch.since")
-		start := viper.GetString("fetch.start")
-		end := viper.GetString("fetch.end")
-
-		tr, err := timerange.Parse(since, start, end)
+		opts, err := fetchOptions()
 		if err != nil {
-			return fmt.Errorf("invalid time range: %w", err)
+			return err
 		}
 
-		log.Infof("Fetching posts from %s to %s", timerange.FormatDate(tr.Start), timerange.FormatDate(tr.End))
+		// Only the Markdown templates write status markers; any other format
+		// would overwrite the draft that --merge is meant to keep
+		if viper.GetBool("fetch.merge") && viper.GetString("fetch.format") != "markdown" {
+			return fmt.Errorf("--merge only works with --format markdown")
+		}
+		if viper.GetBool("fetch.prune") && !viper.GetBool("fetch.merge") {
+			return fmt.Errorf("--prune only works with --merge")
+		}
 
-		// Initialize Mastodon client
-		client, err := mastodon.NewClient(cfg)
-		if err != nil {
-			return fmt.Errorf("failed to create Mastodon client: %w", err)
+		if viper.GetBool("fetch.watch") {
+			if viper.GetBool("fetch.merge") {
+				return fmt.Errorf("--watch can't be combined with --merge")
+			}
+			return runFetchWatch(opts)
 		}
 
-		// Verify credentials and get account info
 		ctx := context.Background()
-		account, err := client.VerifyCredentials(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to verify Mastodon credentials: %w", err)
+		includePinned := viper.GetBool("fetch.include_pinned")
+		if format := viper.GetString("fetch.format"); includePinned || format == "wxr" || format == "epub" {
+			// Share one connection between posts and the profile or account
+			if opts.Source, err = export.OpenSource(ctx, opts); err != nil {
+				return err
+			}
 		}
 
-		log.Infof("Authenticated as @%s", account.Username)
+		posts, err := export.Fetch(ctx, opts)
+		if err != nil {
+			return err
+		}
 
-		// Fetch statuses
-		log.Info("Fetching statuses...")
-		allStatuses := []*mastodonAPI.Status{}
-		var maxID mastodonAPI.ID
+		// Prepare template data
+		data := export.NewTemplateData(opts.Start, opts.End, posts)
 
-		// Pagination loop
-		for {
-			pg := &mastodonAPI.Pagination{
-				MaxID: maxID,
-				Limit: 40,
+		if includePinned {
+			about, err := export.FetchAbout(ctx, opts)
+			if err != nil {
+				return err
 			}
+			about.AddTo(data)
+		}
 
-			statuses, err := client.GetStatuses(ctx, account.ID, pg)
+		outputFile := viper.GetString("fetch.output")
+		switch format := viper.GetString("fetch.format"); format {
+		case "json":
+			// Template data, for previewing templates with serve --data
+			err := writeOutput(outputFile, func(w io.Writer) error {
+				return templates.WriteData(w, data)
+			})
 			if err != nil {
-				return fmt.Errorf("failed to fetch statuses: %w", err)
+				return err
 			}
-
-			if len(statuses) == 0 {
-				break
+		case "csv":
+			err := writeOutput(outputFile, func(w io.Writer) error {
+				return postcsv.Write(w, data.Posts, csvColumns(), viper.GetBool("fetch.csv_bom"))
+			})
+			if err != nil {
+				return err
 			}
-
-			// Filter by time range
-			foundInRange := false
-			for _, status := range statuses {
-				if status.CreatedAt.Before(tr.Start) {
-					// We've gone past our time range
-					break
-				}
-				if status.CreatedAt.After(tr.End) {
-					// Haven't reached our time range yet
-					continue
-				}
-				foundInRange = true
-				allStatuses = append(allStatuses, status)
+		case "epub":
+			if outputFile == "" || outputFile == "-" {
+				return fmt.Errorf("--format epub needs an --output file")
 			}
-
-			// If the last status is before our start time, we're done
-			if len(statuses) > 0 && statuses[len(statuses)-1].CreatedAt.Before(tr.Start) {
-				break
+			if err := writeEPUB(ctx, opts, outputFile, data); err != nil {
+				return err
 			}
-
-			// If we didn't find any in range and we're past the end, keep going
-			if !foundInRange && len(statuses) > 0 && statuses[len(statuses)-1].CreatedAt.Before(tr.End) {
-				break
+		case "wxr":
+			site, err := wxrSite(ctx, opts)
+			if err != nil {
+				return err
 			}
-
-			maxID = statuses[len(statuses)-1].ID
-		}
-
-		log.Infof("Found %d statuses in time range", len(allStatuses))
-
-		// Apply filters
-		filtered := filterStatuses(allStatuses,
-			viper.GetBool("fetch.exclude_replies"),
-			viper.GetBool("fetch.exclude_boosts"),
-			viper.GetString("fetch.visibility"),
-			viper.GetBool("output.public_only"),
-		)
-
-		log.Infof("After filtering: %d statuses", len(filtered))
-
-		// Convert to template format
-		posts := mastodon.ConvertStatuses(filtered)
-
-		// Fetch favorites unless excluded
-		if !viper.GetBool("fetch.exclude_favorites") {
-			log.Info("Fetching favorites...")
-			allFavorites := []*mastodonAPI.Status{}
-			maxID = "" // Reset maxID for favorites pagination
-
-			// Pagination loop for favorites with smart stopping
-			consecutiveEmptyPages := 0
-			maxConsecutiveEmpty := 2 // Stop after 2 pages with no matches
-			maxTotalPages := 3       // Safety limit: ~120 favorites
-
-			for pageCount := 0; pageCount < maxTotalPages; pageCount++ {
-				pg := &mastodonAPI.Pagination{
-					MaxID: maxID,
-					Limit: 40,
-				}
-
-				favorites, err := client.GetFavourites(ctx, pg)
-				if err != nil {
-					return fmt.Errorf("failed to fetch favourites: %w", err)
-				}
-
-				if len(favorites) == 0 {
-					break
-				}
-
-				// Filter by time range
-				foundInRange := false
-				for _, status := range favorites {
-					if status.CreatedAt.Before(tr.Start) {
-						continue
-					}
-					if status.CreatedAt.After(tr.End) {
-						continue
-					}
-					foundInRange = true
-					allFavorites = append(allFavorites, status)
-				}
-
-				// Smart stopping: if no matches in recent pages, we're probably past the date range
-				if !foundInRange {
-					consecutiveEmptyPages++
-					if consecutiveEmptyPages >= maxConsecutiveEmpty {
-						log.Info("No matches in recent pages, stopping favorites pagination")
-						break
-					}
-				} else {
-					consecutiveEmptyPages = 0 // Reset counter on match
-				}
-
-				maxID = favorites[len(favorites)-1].ID
+			err = writeOutput(outputFile, func(w io.Writer) error {
+				return wxr.Write(w, site, posts)
+			})
+			if err != nil {
+				return err
+			}
+		case "markdown", "org", "gemtext":
+			renderer, err := fetchRenderer()
+			if err != nil {
+				return err
 			}
 
-			log.Infof("Found %d favorites in time range", len(allFavorites))
-
-			// Convert favorites and add to posts
-			favoritePosts := mastodon.ConvertFavourites(allFavorites)
-			posts = append(posts, favoritePosts...)
-		}
-
-		// Sort posts based on configuration
-		sortOrder := viper.GetString("output.sort_order")
-		if sortOrder == "" {
-			sortOrder = "asc" // Default to oldest first
-		}
-		sortPosts(posts, sortOrder)
-
-		// Prepare template data
-		data := &templates.TemplateData{
-			StartDate: timerange.FormatDate(tr.Start),
-			EndDate:   timerange.FormatDate(tr.End),
-			Posts:     posts,
-			Days:      templates.GroupPostsByDay(posts),
-		}
-
-		// Initialize template renderer
-		templatePath := cfg.Output.Template
-		renderer, err := templates.NewRenderer(templatePath)
-		if err != nil {
-			return fmt.Errorf("failed to initialize template: %w", err)
-		}
+			if viper.GetBool("fetch.merge") {
+				return mergeOutput(renderer, outputFile, data)
+			}
 
-		// Render to output
-		outputFile := viper.GetString("fetch.output")
-		if err := renderer.RenderToFile(outputFile, data); err != nil {
-			return fmt.Errorf("failed to render output: %w", err)
+			// Render to output
+			if err := renderer.RenderToFile(outputFile, data); err != nil {
+				return fmt.Errorf("failed to render output: %w", err)
+			}
+		default:
+			return fmt.Errorf("unknown fetch format %q (expected markdown, org, gemtext, json, csv, wxr, or epub)", format)
 		}
 
 		if outputFile != "" && outputFile != "-" {
@@ -226,82 +179,324 @@ Example usage:
 func init() {
 	rootCmd.AddCommand(fetchCmd)
 
-	// Time range flags
-	fetchCmd.Flags().String("since", "", "Time period to fetch (e.g., '24h', '7d')")
-	fetchCmd.Flags().String("start", "", "Start date (YYYY-MM-DD)")
-	fetchCmd.Flags().String("end", "", "End date (YYYY-MM-DD)")
+	// Time range, filter, and sort flags
+	addPipelineFlags(fetchCmd)
 
 	// Output flags
 	fetchCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
-	fetchCmd.Flags().String("sort-order", "asc", "Sort order: 'asc' (oldest first) or 'desc' (newest first)")
-	fetchCmd.Flags().Bool("public-only", true, "Only include public posts (exclude direct/private)")
-
-	// Filter flags
-	fetchCmd.Flags().Bool("exclude-replies", false, "Exclude reply posts")
-	fetchCmd.Flags().Bool("exclude-boosts", false, "Exclude boosted posts")
-	fetchCmd.Flags().Bool("exclude-favorites", false, "Exclude favorited posts")
-	fetchCmd.Flags().String("visibility", "", "Filter by visibility (comma-separated: public,unlisted,private)")
+	fetchCmd.Flags().String("format", "markdown", "Output format: 'markdown', 'org', or 'gemtext' (rendered through the template), 'json' (template data), 'csv' (spreadsheet), 'wxr' (WordPress import), or 'epub' (ebook)")
+	fetchCmd.Flags().String("template", "", "Template: built-in name ('default', 'link-roundup', 'about', 'org', 'gemtext') or path to a custom file")
+	fetchCmd.Flags().Bool("link-roundup", false, "Use the built-in link roundup template (external links grouped by domain)")
+	fetchCmd.Flags().Bool("include-pinned", false, "Fetch the profile, pinned posts, and featured hashtags (see the 'about' template)")
+	fetchCmd.Flags().Bool("skip-media", false, "Link to images instead of downloading them into the epub")
+	fetchCmd.Flags().String("columns", "", "Comma-separated CSV columns (default: "+strings.Join(postcsv.DefaultColumns(), ",")+")")
+	fetchCmd.Flags().Bool("csv-bom", false, "Write CSV for Excel: a UTF-8 byte order mark, and a leading ' on text that would read as a formula")
+
+	// Merge flags
+	fetchCmd.Flags().Bool("merge", false, "Update posts in an existing output file, keeping hand edits")
+	fetchCmd.Flags().Bool("prune", false, "With --merge, remove posts that are no longer fetched, unless edited")
+
+	// Watch flags
+	fetchCmd.Flags().Bool("watch", false, "Keep polling and append new posts to the output")
+	fetchCmd.Flags().Duration("interval", 5*time.Minute, "Time between polls with --watch")
+	fetchCmd.Flags().String("state", "", "File of post IDs already written with --watch (default: <output>.seen.json)")
 
 	// Bind flags to viper
-	_ = viper.BindPFlag("fetch.since", fetchCmd.Flags().Lookup("since"))
-	_ = viper.BindPFlag("fetch.start", fetchCmd.Flags().Lookup("start"))
-	_ = viper.BindPFlag("fetch.end", fetchCmd.Flags().Lookup("end"))
 	_ = viper.BindPFlag("fetch.output", fetchCmd.Flags().Lookup("output"))
-	_ = viper.BindPFlag("output.sort_order", fetchCmd.Flags().Lookup("sort-order"))
-	_ = viper.BindPFlag("output.public_only", fetchCmd.Flags().Lookup("public-only"))
-	_ = viper.BindPFlag("fetch.exclude_replies", fetchCmd.Flags().Lookup("exclude-replies"))
-	_ = viper.BindPFlag("fetch.exclude_boosts", fetchCmd.Flags().Lookup("exclude-boosts"))
-	_ = viper.BindPFlag("fetch.exclude_favorites", fetchCmd.Flags().Lookup("exclude-favorites"))
-	_ = viper.BindPFlag("fetch.visibility", fetchCmd.Flags().Lookup("visibility"))
+	_ = viper.BindPFlag("fetch.format", fetchCmd.Flags().Lookup("format"))
+	_ = viper.BindPFlag("output.template", fetchCmd.Flags().Lookup("template"))
+	_ = viper.BindPFlag("output.link_roundup", fetchCmd.Flags().Lookup("link-roundup"))
+	_ = viper.BindPFlag("fetch.include_pinned", fetchCmd.Flags().Lookup("include-pinned"))
+	_ = viper.BindPFlag("fetch.skip_media", fetchCmd.Flags().Lookup("skip-media"))
+	_ = viper.BindPFlag("fetch.csv_columns", fetchCmd.Flags().Lookup("columns"))
+	_ = viper.BindPFlag("fetch.csv_bom", fetchCmd.Flags().Lookup("csv-bom"))
+	_ = viper.BindPFlag("fetch.merge", fetchCmd.Flags().Lookup("merge"))
+	_ = viper.BindPFlag("fetch.prune", fetchCmd.Flags().Lookup("prune"))
+	_ = viper.BindPFlag("fetch.watch", fetchCmd.Flags().Lookup("watch"))
+	_ = viper.BindPFlag("fetch.interval", fetchCmd.Flags().Lookup("interval"))
+	_ = viper.BindPFlag("fetch.state", fetchCmd.Flags().Lookup("state"))
 }
 
-// filterStatuses applies visibility, reply, and boost filters to statuses
-func filterStatuses(statuses []*mastodonAPI.Status, excludeReplies, excludeBoosts bool, visibilityFilter string, publicOnly bool) []*mastodonAPI.Status {
-	filtered := []*mastodonAPI.Status{}
-
-	// Parse visibility filter
-	visibilities := map[string]bool{}
-	if visibilityFilter != "" {
-		for _, v := range strings.Split(visibilityFilter, ",") {
-			visibilities[strings.TrimSpace(v)] = true
+// csvColumns returns the CSV columns from --columns, or nil for the defaults
+func csvColumns() []string {
+	var columns []string
+	for _, name := range strings.Split(viper.GetString("fetch.csv_columns"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			columns = append(columns, name)
 		}
 	}
+	return columns
+}
 
-	for _, status := range statuses {
-		// Filter replies
-		if excludeReplies && status.InReplyToID != nil {
-			continue
-		}
+// fetchRenderer loads the template chosen by the flags and config, defaulting
+// to the built-in template for the org and gemtext formats
+func fetchRenderer() (*export.Renderer, error) {
+	templatePath := viper.GetString("output.template")
+	if viper.GetBool("output.link_roundup") {
+		templatePath = "link-roundup"
+	}
+	if format := viper.GetString("fetch.format"); templatePath == "" && format != "markdown" {
+		templatePath = format
+	}
+	renderer, err := export.NewRenderer(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize template: %w", err)
+	}
+	return renderer, nil
+}
 
-		// Filter boosts
-		if excludeBoosts && status.Reblog != nil {
-			continue
-		}
+// mergeOutput renders data with status markers and merges it into the output
+// file, so posts are refreshed without losing hand edits
+func mergeOutput(renderer *export.Renderer, outputFile string, data *templates.TemplateData) error {
+	log := GetLogger()
 
-		// Filter by public_only (exclude direct and private posts)
-		if publicOnly && (status.Visibility == "direct" || status.Visibility == "private") {
-			continue
-		}
+	if outputFile == "" || outputFile == "-" {
+		return fmt.Errorf("--merge needs an --output file")
+	}
+
+	data.Markers = true
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		return fmt.Errorf("failed to render output: %w", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("<!-- status:")) && len(data.Posts) > 0 {
+		return fmt.Errorf("the template doesn't write status markers, so --merge can't tell posts apart (see {{if $.Markers}} in the default template)")
+	}
 
-		// Filter by visibility
-		if len(visibilities) > 0 && !visibilities[string(status.Visibility)] {
-			continue
+	result, changed, err := merge.File(outputFile, buf.Bytes(), viper.GetBool("fetch.prune"))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Merged into %s: %d added, %d updated, %d kept with edits, %d removed",
+		outputFile, result.Added, result.Updated, result.Edited, result.Removed)
+	if !changed {
+		log.Infof("%s is unchanged", outputFile)
+	}
+	return nil
+}
+
+// runFetchWatch appends new posts to the output until stopped
+func runFetchWatch(opts export.FetchOptions) error {
+	if format := viper.GetString("fetch.format"); format != "markdown" && format != "org" && format != "gemtext" {
+		return fmt.Errorf("--watch only supports templated formats (markdown, org, gemtext), not %q", format)
+	}
+	if viper.GetBool("fetch.include_pinned") {
+		return fmt.Errorf("--watch can't be combined with --include-pinned")
+	}
+	// New posts are appended to the end, so they must come oldest first
+	if opts.SortOrder == "desc" {
+		return fmt.Errorf("--watch can't be combined with --sort-order desc")
+	}
+
+	renderer, err := fetchRenderer()
+	if err != nil {
+		return err
+	}
+
+	output := viper.GetString("fetch.output")
+	statePath := viper.GetString("fetch.state")
+	if statePath == "" {
+		statePath = watchStatePath(output)
+	}
+
+	watcher, err := newFetchWatcher(opts, renderer, output, statePath)
+	if err != nil {
+		return err
+	}
+	return watcher.run(viper.GetDuration("fetch.interval"))
+}
+
+// pipelineFlags maps the flags shared by commands that run the fetch pipeline to viper keys
+var pipelineFlags = map[string]string{
+	"since":                "fetch.since",
+	"start":                "fetch.start",
+	"end":                  "fetch.end",
+	"sort-order":           "output.sort_order",
+	"public-only":          "output.public_only",
+	"emoji":                "output.emoji",
+	"exclude-replies":      "fetch.exclude_replies",
+	"exclude-boosts":       "fetch.exclude_boosts",
+	"exclude-favorites":    "fetch.exclude_favorites",
+	"include-edit-history": "fetch.include_edit_history",
+	"visibility":           "fetch.visibility",
+	"account":              "fetch.account",
+	"hashtag":              "fetch.hashtag",
+	"source":               "fetch.source",
+	"min-engagement":       "fetch.min_engagement",
+}
+
+// addPipelineFlags registers the time range, filter, and sort flags on a command
+// The command must call bindPipelineFlags in its PreRun
+func addPipelineFlags(cmd *cobra.Command) {
+	// Source flags
+	cmd.Flags().String("account", "", "Export another account's posts instead of your own (e.g., '@someone@instance')")
+	cmd.Flags().String("hashtag", "", "Export the public timeline for a hashtag instead of an account (no access token needed)")
+	cmd.Flags().String("source", "", "Export a timeline instead of your account: 'home' or 'list:<name-or-id>'")
+
+	// Time range flags
+	cmd.Flags().String("since", "", "Time period to fetch (e.g., '24h', '7d')")
+	cmd.Flags().String("start", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().String("end", "", "End date (YYYY-MM-DD)")
+
+	// Output flags
+	cmd.Flags().String("sort-order", "asc", "Sort order: 'asc' (oldest first) or 'desc' (newest first)")
+	cmd.Flags().Bool("public-only", true, "Only include public posts (exclude direct/private)")
+	cmd.Flags().String("emoji", "shortcode", "Custom emoji rendering: 'shortcode' (keep :name:), 'image' (markdown image), or 'drop'")
+
+	// Filter flags
+	cmd.Flags().Bool("exclude-replies", false, "Exclude reply posts")
+	cmd.Flags().Bool("exclude-boosts", false, "Exclude boosted posts")
+	cmd.Flags().Bool("exclude-favorites", false, "Exclude favorited posts")
+	cmd.Flags().Bool("include-edit-history", false, "Fetch every version of edited posts")
+	cmd.Flags().String("visibility", "", "Filter by visibility (comma-separated: public,unlisted,private)")
+	cmd.Flags().Int64("min-engagement", 0, "Only include posts with at least this many replies, boosts, and favourites combined")
+}
+
+// bindPipelineFlags binds the shared pipeline flags of the running command to viper
+// Binding happens at run time because several commands share the same viper keys,
+// and viper only keeps the most recent binding for each key
+func bindPipelineFlags(cmd *cobra.Command) {
+	for flag, key := range pipelineFlags {
+		_ = viper.BindPFlag(key, cmd.Flags().Lookup(flag))
+	}
+}
+
+// fetchOptions builds export options from the bound flags and config
+func fetchOptions() (export.FetchOptions, error) {
+	log := GetLogger()
+
+	account := viper.GetString("fetch.account")
+	hashtag := viper.GetString("fetch.hashtag")
+	timeline := viper.GetString("fetch.source")
+	sources := 0
+	for _, v := range []string{account, hashtag, timeline} {
+		if v != "" {
+			sources++
 		}
+	}
+	if sources > 1 {
+		return export.FetchOptions{}, fmt.Errorf("only one of --account, --hashtag, and --source can be used")
+	}
+
+	// Parse time range
+	since := viper.GetString("fetch.since")
+	start := viper.GetString("fetch.start")
+	end := viper.GetString("fetch.end")
+
+	tr, err := timerange.Parse(since, start, end)
+	if err != nil {
+		return export.FetchOptions{}, fmt.Errorf("invalid time range: %w", err)
+	}
+
+	log.Infof("Fetching posts from %s to %s", timerange.FormatDate(tr.Start), timerange.FormatDate(tr.End))
+
+	return export.FetchOptions{
+		Server:             viper.GetString("mastodon.server"),
+		AccessToken:        viper.GetString("mastodon.access_token"),
+		Account:            account,
+		Hashtag:            hashtag,
+		Timeline:           timeline,
+		Start:              tr.Start,
+		End:                tr.End,
+		ExcludeReplies:     viper.GetBool("fetch.exclude_replies"),
+		ExcludeBoosts:      viper.GetBool("fetch.exclude_boosts"),
+		ExcludeFavorites:   viper.GetBool("fetch.exclude_favorites"),
+		Visibility:         viper.GetString("fetch.visibility"),
+		PublicOnly:         viper.GetBool("output.public_only"),
+		IncludeEditHistory: viper.GetBool("fetch.include_edit_history"),
+		MinEngagement:      viper.GetInt64("fetch.min_engagement"),
+		EmojiMode:          viper.GetString("output.emoji"),
+		SortOrder:          viper.GetString("output.sort_order"),
+		Log:                log,
+	}, nil
+}
 
-		filtered = append(filtered, status)
+// writeOutput opens a file, or stdout if empty or "-", and writes to it
+// Closing the file can be what fails to flush it, so that error is returned too
+func writeOutput(outputFile string, write func(w io.Writer) error) error {
+	if outputFile == "" || outputFile == "-" {
+		return write(os.Stdout)
 	}
 
-	return filtered
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create output file %s: %w", outputFile, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", outputFile, err)
+	}
+	return nil
 }
 
-// sortPosts sorts posts by creation time
-// sortOrder: "asc" for oldest first (forward chronological), "desc" for newest first
-func sortPosts(posts []templates.Post, sortOrder string) {
-	sort.Slice(posts, func(i, j int) bool {
-		if sortOrder == "desc" {
-			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+// writeEPUB writes data as an ebook titled after the source's account, with
+// images downloaded and embedded unless skipped
+func writeEPUB(ctx context.Context, opts export.FetchOptions, outputFile string, data *templates.TemplateData) error {
+	log := GetLogger()
+
+	book := epub.Book{
+		Title:      fmt.Sprintf("Mastodon posts from %s to %s", data.StartDate, data.EndDate),
+		Author:     "Mastodon",
+		Identifier: fmt.Sprintf("%s#%s..%s", opts.Server, data.StartDate, data.EndDate),
+		Modified:   fetchNow(),
+	}
+	account, err := opts.Source.Account(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	if account != nil {
+		book.Title = fmt.Sprintf("@%s on Mastodon, %s to %s", account.Acct, data.StartDate, data.EndDate)
+		book.Author = account.DisplayName
+		book.Identifier = fmt.Sprintf("%s#%s..%s", account.URL, data.StartDate, data.EndDate)
+	}
+
+	images := map[string]string{}
+	if !viper.GetBool("fetch.skip_media") {
+		dir, err := os.MkdirTemp("", "mastodon-to-markdown-epub-")
+		if err != nil {
+			return fmt.Errorf("failed to create media directory: %w", err)
 		}
-		// Default to "asc" - oldest first
-		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+		defer os.RemoveAll(dir)
+
+		downloader := media.NewDownloader(dir)
+		for i, url := range epub.ImageURLs(data) {
+			file, err := downloader.Download(ctx, url, fmt.Sprintf("image-%d", i+1))
+			if err != nil {
+				// Keep going; the ebook links to images it couldn't embed
+				log.Warnf("Linking instead of embedding image: %v", err)
+				continue
+			}
+			images[url] = file
+		}
+	}
+
+	return writeOutput(outputFile, func(w io.Writer) error {
+		return epub.Write(w, book, data, images)
 	})
 }
+
+// wxrSite describes a WordPress export of the source's posts
+func wxrSite(ctx context.Context, opts export.FetchOptions) (wxr.Site, error) {
+	site := wxr.Site{
+		Title:       fmt.Sprintf("Mastodon posts from %s to %s", timerange.FormatDate(opts.Start), timerange.FormatDate(opts.End)),
+		Link:        opts.Server,
+		PubDate:     opts.End,
+		AuthorLogin: "mastodon",
+	}
+
+	account, err := opts.Source.Account(ctx)
+	if err != nil {
+		return site, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account != nil {
+		site.Link = account.URL
+		site.Description = "Posts by @" + account.Acct
+		site.AuthorLogin = account.Username
+		site.AuthorName = account.DisplayName
+	}
+	return site, nil
+}
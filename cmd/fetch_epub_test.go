@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchEPUBGolden(t *testing.T) {
+	server := startFakeServer(t)
+	fetchNow = func() time.Time { return time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { fetchNow = time.Now })
+
+	output := filepath.Join(t.TempDir(), "posts.epub")
+	runCommand(t, server.URL, "fetch", "--start", "2025-11-03", "--end", "2025-11-10",
+		"--format", "epub", "--skip-media", "--output", output)
+
+	book, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatalf("failed to open epub: %v", err)
+	}
+	defer book.Close()
+
+	var names strings.Builder
+	for _, f := range book.File {
+		fmt.Fprintf(&names, "%s %d\n", f.Name, f.Method)
+	}
+	assertGolden(t, "fetch-epub-files.txt", []byte(names.String()))
+
+	for _, name := range []string{"OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/day-004.xhtml"} {
+		f, err := book.Open(name)
+		if err != nil {
+			t.Fatalf("epub is missing %s: %v", name, err)
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		assertGolden(t, "fetch-epub-"+filepath.Base(name), content)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchMergeKeepsEdits(t *testing.T) {
+	server := startFakeServer(t)
+	output := filepath.Join(t.TempDir(), "draft.md")
+	args := []string{"fetch", "--merge", "--output", output, "--start", "2025-11-03"}
+
+	// A draft of the first few days
+	runCommand(t, server.URL, append(args, "--end", "2025-11-06")...)
+
+	// Hand edits: commentary between posts and a rewritten post
+	draft, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read draft: %v", err)
+	}
+	edited := strings.Replace(string(draft), "## 2025-11-04\n", "## 2025-11-04\n\nA quiet Tuesday.\n", 1)
+	edited = strings.Replace(edited, "Spoilers for the finale below.", "Spoilers for the finale below. (I was wrong about the ending.)", 1)
+	if edited == string(draft) {
+		t.Fatal("edits didn't apply to the draft")
+	}
+	if err := os.WriteFile(output, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refreshed later in the week, with more posts
+	runCommand(t, server.URL, append(args, "--end", "2025-11-10")...)
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read draft: %v", err)
+	}
+	assertGolden(t, "fetch-merge.md", got)
+}
+
+func TestFetchMergeRejectsOtherFormats(t *testing.T) {
+	server := startFakeServer(t)
+	output := filepath.Join(t.TempDir(), "draft.md")
+	if err := os.WriteFile(output, []byte("My draft\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--merge", "--format", "csv"},
+		{"--merge", "--format", "epub"},
+		{"--merge", "--format", "org"},
+		{"--prune"},
+	} {
+		args = append([]string{"fetch", "--start", "2025-11-03", "--end", "2025-11-10", "--output", output}, args...)
+		if err := executeCommand(t, server.URL, "fake", args...); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+		if draft, _ := os.ReadFile(output); string(draft) != "My draft\n" {
+			t.Errorf("%v changed the draft to %q", args, draft)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/fakeserver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// startFakeServer serves the built-in fixtures with a small page size so pagination is exercised
+func startFakeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	fake, err := fakeserver.New(fakeserver.DefaultFixtures())
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	fake.PageSize = 3
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCommand executes the root command with a config file pointing at the fake server
+func runCommand(t *testing.T, serverURL string, args ...string) {
+	t.Helper()
+	runCommandAs(t, serverURL, "fake", args...)
+}
+
+// runCommandAs is runCommand with a specific access token, empty for none
+func runCommandAs(t *testing.T, serverURL, accessToken string, args ...string) {
+	t.Helper()
+	if err := executeCommand(t, serverURL, accessToken, args...); err != nil {
+		t.Fatalf("command %v failed: %v", args, err)
+	}
+}
+
+// executeCommand runs the root command against serverURL and returns its error
+func executeCommand(t *testing.T, serverURL, accessToken string, args ...string) error {
+	t.Helper()
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	config := fmt.Sprintf("mastodon:\n  server: %q\n  access_token: %q\n", serverURL, accessToken)
+	if err := os.WriteFile(configFile, []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// Flag values persist between executions in the same process, so reset them
+	resetFlags(rootCmd)
+	t.Cleanup(func() { resetFlags(rootCmd) })
+
+	rootCmd.SetArgs(append([]string{"--config", configFile}, args...))
+	return rootCmd.Execute()
+}
+
+// resetFlags restores every flag on cmd and its subcommands to its default value
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		_ = f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// assertGolden compares output against a golden file, rewriting it with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("failed to create golden dir: %v", err)
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("output does not match %s (run with -update to accept)\n--- got ---\n%s", golden, got)
+	}
+}
+
+func TestFetchGolden(t *testing.T) {
+	server := startFakeServer(t)
+
+	tests := []struct {
+		name      string
+		args      []string
+		anonymous bool // Run without an access token
+	}{
+		{name: "default.md"},
+		{name: "all-visibilities-desc.md", args: []string{"--public-only=false", "--sort-order", "desc"}},
+		{name: "no-replies-boosts-favorites.md", args: []string{"--exclude-replies", "--exclude-boosts", "--exclude-favorites"}},
+		{name: "edit-history.md", args: []string{"--include-edit-history"}},
+		{name: "emoji-image.md", args: []string{"--emoji", "image"}},
+		{name: "link-roundup.md", args: []string{"--link-roundup"}},
+		{name: "account.md", args: []string{"--account", "@alice@fake.example"}},
+		{name: "hashtag.md", args: []string{"--hashtag", "#golang"}, anonymous: true},
+		{name: "home.md", args: []string{"--source", "home"}},
+		{name: "about.md", args: []string{"--include-pinned", "--template", "about", "--emoji", "drop"}},
+		{name: "list-min-engagement.md", args: []string{"--source", "list:go", "--min-engagement", "10"}},
+		{name: "data.json", args: []string{"--format", "json", "--include-pinned"}},
+		{name: "wxr.xml", args: []string{"--format", "wxr", "--public-only=false"}},
+		{name: "org.org", args: []string{"--format", "org", "--include-edit-history"}},
+		{name: "gemtext.gmi", args: []string{"--format", "gemtext", "--include-edit-history"}},
+		{name: "csv.csv", args: []string{"--format", "csv", "--public-only=false"}},
+		{name: "csv-columns.csv", args: []string{"--format", "csv", "--columns", "id, type,content,author", "--csv-bom"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "out.md")
+			args := append([]string{"fetch", "--start", "2025-11-03", "--end", "2025-11-10", "--output", output}, tt.args...)
+			token := "fake"
+			if tt.anonymous {
+				token = ""
+			}
+			runCommandAs(t, server.URL, token, args...)
+
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			assertGolden(t, "fetch-"+tt.name, got)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/journal"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	"github.com/sirupsen/logrus"
+)
+
+// watchLookback is how far before the previous poll each poll starts, since
+// posts from other servers and favourites can arrive late
+const watchLookback = time.Hour
+
+// fetchWatcher appends posts it hasn't written before to a journal file
+type fetchWatcher struct {
+	opts      export.FetchOptions
+	renderer  *export.Renderer
+	output    string // Empty or "-" for stdout
+	statePath string
+	state     *journal.State
+	now       func() time.Time
+	log       logrus.FieldLogger
+}
+
+// newFetchWatcher loads the seen posts from statePath
+func newFetchWatcher(opts export.FetchOptions, renderer *export.Renderer, output, statePath string) (*fetchWatcher, error) {
+	state, err := journal.LoadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fetchWatcher{
+		opts:      opts,
+		renderer:  renderer,
+		output:    output,
+		statePath: statePath,
+		state:     state,
+		now:       time.Now,
+		log:       GetLogger(),
+	}, nil
+}
+
+// watchStatePath returns the default state file for an output file
+func watchStatePath(output string) string {
+	if output == "" || output == "-" {
+		return "mastodon-to-markdown.seen.json"
+	}
+	return output + ".seen.json"
+}
+
+// run polls every interval until SIGINT or SIGTERM
+// The first poll must succeed, later failures are logged and retried on the next tick
+func (w *fetchWatcher) run(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", interval)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Share one connection between polls
+	source, err := export.OpenSource(ctx, w.opts)
+	if err != nil {
+		return err
+	}
+	w.opts.Source = source
+
+	if err := w.poll(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.log.Info("Stopped watching")
+			return nil
+		case <-ticker.C:
+			if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+				w.log.Warnf("Poll failed, retrying in %s: %v", interval, err)
+			}
+		}
+	}
+}
+
+// poll fetches posts up to now, appends the ones not written before, and records them
+func (w *fetchWatcher) poll(ctx context.Context) error {
+	opts := w.opts
+	opts.End = w.now()
+
+	posts, err := export.Fetch(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	unseen := w.state.Unseen(posts)
+	w.log.Infof("Found %d new posts", len(unseen))
+	if len(unseen) > 0 {
+		// Only the new posts, without the title and summary, and without the
+		// heading of a day already written
+		data := export.NewTemplateData(opts.Start, opts.End, unseen)
+		data.Appending = true
+		if data.Days[0].Date == w.state.LastDay {
+			data.Days[0].Continued = true
+		}
+
+		// Render first, so a template error doesn't leave a partial entry
+		var buf bytes.Buffer
+		if err := w.renderer.Render(&buf, data); err != nil {
+			return fmt.Errorf("failed to render output: %w", err)
+		}
+		if err := w.append(buf.Bytes()); err != nil {
+			return err
+		}
+
+		// Posts are recorded after they're written, so a crash in between
+		// repeats them rather than losing them
+		w.state.Record(unseen, opts.End.UTC().Format(time.RFC3339))
+		w.state.LastDay = data.Days[len(data.Days)-1].Date
+		if err := w.state.Save(w.statePath); err != nil {
+			return err
+		}
+	}
+
+	// Later polls only look back a little before this one
+	if next := opts.End.Add(-watchLookback); next.After(w.opts.Start) {
+		w.opts.Start = next
+	}
+	return nil
+}
+
+// append writes rendered posts to the end of the output
+func (w *fetchWatcher) append(rendered []byte) error {
+	var out io.Writer = os.Stdout
+	if w.output != "" && w.output != "-" {
+		f, err := os.OpenFile(w.output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open output file %s: %w", w.output, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if _, err := out.Write(rendered); err != nil {
+		return fmt.Errorf("failed to append to output: %w", err)
+	}
+	return nil
+}
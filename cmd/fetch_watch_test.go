@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+)
+
+func TestFetchWatchAppendsNewPosts(t *testing.T) {
+	server := startFakeServer(t)
+	dir := t.TempDir()
+	output := filepath.Join(dir, "journal.md")
+	statePath := watchStatePath(output)
+
+	renderer, err := export.NewRenderer("")
+	if err != nil {
+		t.Fatalf("failed to load template: %v", err)
+	}
+	opts := export.FetchOptions{
+		Server:      server.URL,
+		AccessToken: "fake",
+		Start:       time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+		PublicOnly:  true,
+		SortOrder:   "asc",
+	}
+
+	poll := func(now time.Time) {
+		t.Helper()
+		// A new watcher each time, as if restarted, to check the state file is used
+		watcher, err := newFetchWatcher(opts, renderer, output, statePath)
+		if err != nil {
+			t.Fatalf("failed to create watcher: %v", err)
+		}
+		watcher.now = func() time.Time { return now }
+		if err := watcher.poll(context.Background()); err != nil {
+			t.Fatalf("poll at %s failed: %v", now, err)
+		}
+	}
+
+	// The second poll only appends posts since the first, continuing 2025-11-05
+	// without repeating its heading, and the third nothing
+	poll(time.Date(2025, 11, 5, 9, 0, 0, 0, time.UTC))
+	poll(time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC))
+	poll(time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC))
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	assertGolden(t, "fetch-watch.md", got)
+
+	if _, err := os.Stat(statePath); err != nil {
+		t.Errorf("state file was not written: %v", err)
+	}
+}
+
+func TestFetchWatchRejectsNewestFirst(t *testing.T) {
+	server := startFakeServer(t)
+	output := filepath.Join(t.TempDir(), "journal.md")
+
+	err := executeCommand(t, server.URL, "fake", "fetch", "--watch", "--sort-order", "desc", "--output", output)
+	if err == nil {
+		t.Fatal("--watch with --sort-order desc should fail")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("output was written: %v", err)
+	}
+}
@@ -36,6 +36,9 @@ output:
 
   # Template to use for output
   # Leave empty or omit to use built-in default template
+  # Set to "link-roundup" to use the built-in link roundup template
+  # Set to "org" or "gemtext" for Org-mode or Gemtext output (or use fetch --format)
+  # Set to "about" for an about page (with fetch.include_pinned)
   # Set to a filename to use a custom template file (e.g., "mastodon-to-markdown.md")
   template: ""
 
@@ -47,6 +50,12 @@ output:
   # Default: true
   public_only: true
 
+  # How to render custom emoji like :blobcat: in content and display names
+  # "shortcode" keeps the :shortcode: text, "image" renders a markdown image
+  # pointing at the emoji URL, "drop" removes them
+  # Default: "shortcode"
+  emoji: "shortcode"
+
 # Fetch configuration
 fetch:
   # Exclude reply posts from output
@@ -61,6 +70,19 @@ fetch:
   # Default: false
   exclude_favorites: false
 
+  # Fetch every version of edited posts (one extra request per edited post)
+  # Default: false
+  include_edit_history: false
+
+  # Fetch the profile, pinned posts, and featured hashtags (see the "about" template)
+  # Default: false
+  include_pinned: false
+
+  # Only include posts with at least this many replies, boosts, and favourites
+  # combined, e.g. to keep list digests short
+  # Default: 0 (no threshold)
+  min_engagement: 0
+
   # Filter by visibility (comma-separated: public,unlisted,private)
   # Leave empty to include all visibilities (subject to public_only setting)
   visibility: ""
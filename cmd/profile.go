@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+	"github.com/lmorchard/mastodon-to-markdown/internal/media"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// profileCmd groups commands that work with an account profile
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Work with your Mastodon profile",
+}
+
+// profileExportCmd represents the profile export command
+var profileExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the account profile through a template or as JSON",
+	Long: `Export the full account profile: display name, bio converted from HTML,
+metadata fields with verification status, follower and following counts,
+featured hashtags, and pinned posts. The avatar and header images, and custom
+emoji images with --emoji image, are downloaded next to the output file, so a
+static "about" page can stay in sync with Mastodon.
+
+Output formats:
+  markdown  Rendered through a template (built-in 'about', or --template)
+  json      The profile as JSON
+
+Example usage:
+  mastodon-to-markdown profile export --output about/index.md
+  mastodon-to-markdown profile export --format json --output profile.json
+  mastodon-to-markdown profile export --account @someone@example.social --skip-media`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+
+		log.Info("Running profile export command")
+
+		format := viper.GetString("profile.format")
+		if format != "markdown" && format != "json" {
+			return fmt.Errorf("unknown profile format %q (expected markdown or json)", format)
+		}
+
+		ctx := context.Background()
+		about, err := export.FetchAbout(ctx, export.FetchOptions{
+			Server:      viper.GetString("mastodon.server"),
+			AccessToken: viper.GetString("mastodon.access_token"),
+			Account:     viper.GetString("profile.account"),
+			PublicOnly:  viper.GetBool("output.public_only"),
+			EmojiMode:   viper.GetString("output.emoji"),
+			Log:         log,
+		})
+		if err != nil {
+			return err
+		}
+
+		outputFile := viper.GetString("profile.output")
+		if !viper.GetBool("profile.skip_media") {
+			emoji := viper.GetString("output.emoji") == export.EmojiImage
+			downloadProfileMedia(ctx, about, outputFile, viper.GetString("profile.media_dir"), emoji)
+		}
+
+		if format == "json" {
+			if err := writeProfileJSON(outputFile, about.Profile); err != nil {
+				return err
+			}
+		} else {
+			templatePath := viper.GetString("profile.template")
+			if templatePath == "" {
+				templatePath = "about"
+			}
+			renderer, err := templates.NewRenderer(templatePath)
+			if err != nil {
+				return fmt.Errorf("failed to initialize template: %w", err)
+			}
+
+			data := &templates.TemplateData{}
+			about.AddTo(data)
+			if err := renderer.RenderToFile(outputFile, data); err != nil {
+				return fmt.Errorf("failed to render output: %w", err)
+			}
+		}
+
+		if outputFile != "" && outputFile != "-" {
+			log.Infof("Profile written to %s", outputFile)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileExportCmd)
+
+	profileExportCmd.Flags().String("format", "markdown", "Output format: 'markdown' or 'json'")
+	profileExportCmd.Flags().String("template", "", "Template for markdown format: built-in name or path to a custom file (default: about)")
+	profileExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	profileExportCmd.Flags().String("account", "", "Export another account's profile (e.g., '@someone@instance')")
+	profileExportCmd.Flags().String("media-dir", "", "Directory for the avatar and header images (default: next to the output file)")
+	profileExportCmd.Flags().Bool("skip-media", false, "Link to the avatar, header, and emoji images instead of downloading them")
+
+	// Bind flags to viper
+	_ = viper.BindPFlag("profile.format", profileExportCmd.Flags().Lookup("format"))
+	_ = viper.BindPFlag("profile.template", profileExportCmd.Flags().Lookup("template"))
+	_ = viper.BindPFlag("profile.output", profileExportCmd.Flags().Lookup("output"))
+	_ = viper.BindPFlag("profile.account", profileExportCmd.Flags().Lookup("account"))
+	_ = viper.BindPFlag("profile.media_dir", profileExportCmd.Flags().Lookup("media-dir"))
+	_ = viper.BindPFlag("profile.skip_media", profileExportCmd.Flags().Lookup("skip-media"))
+}
+
+// downloadProfileMedia downloads the avatar and header images, setting their
+// paths relative to the output file on the profile, and with emoji set, the
+// custom emoji images in the profile and pinned posts
+// Failed downloads are logged and the remote image is used instead
+func downloadProfileMedia(ctx context.Context, about *export.About, outputFile, mediaDir string, emoji bool) {
+	log := GetLogger()
+	profile := about.Profile
+
+	outputDir := "."
+	if outputFile != "" && outputFile != "-" {
+		outputDir = filepath.Dir(outputFile)
+	}
+	if mediaDir == "" {
+		mediaDir = outputDir
+	}
+
+	downloader := media.NewDownloader(mediaDir)
+	download := func(url, name string) (string, bool) {
+		path, err := downloader.Download(ctx, url, name)
+		if err != nil {
+			log.Warnf("Using remote %s image: %v", name, err)
+			return "", false
+		}
+		if rel, err := filepath.Rel(outputDir, path); err == nil {
+			path = rel
+		}
+		return filepath.ToSlash(path), true
+	}
+
+	images := []struct {
+		url  string
+		name string
+		file *string
+	}{
+		{profile.Avatar, "avatar", &profile.AvatarFile},
+		{profile.Header, "header", &profile.HeaderFile},
+	}
+
+	for _, image := range images {
+		if image.url == "" {
+			continue
+		}
+		if path, ok := download(image.url, image.name); ok {
+			*image.file = path
+		}
+	}
+
+	if !emoji {
+		return
+	}
+	files := map[string]string{}
+	for i, url := range mastodon.EmojiImageURLs(profile, about.Pinned) {
+		if path, ok := download(url, fmt.Sprintf("emoji-%d", i+1)); ok {
+			files[url] = path
+		}
+	}
+	mastodon.RelinkEmojiImages(profile, about.Pinned, files)
+}
+
+// writeProfileJSON writes the profile as indented JSON to a file, or stdout if empty or "-"
+func writeProfileJSON(outputFile string, profile *templates.Profile) error {
+	return writeOutput(outputFile, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(profile); err != nil {
+			return fmt.Errorf("failed to encode profile: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileExportGolden(t *testing.T) {
+	server := startFakeServer(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "default.md"},
+		{name: "format-json.json", args: []string{"--format", "json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), tt.name)
+			args := append([]string{"profile", "export", "--skip-media", "--output", output}, tt.args...)
+			runCommand(t, server.URL, args...)
+
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			assertGolden(t, "profile-"+tt.name, got)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/publish"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// publishCmd groups commands that fetch, render, and publish in one step
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Fetch posts, render them, and publish the result",
+}
+
+// publishGitCmd represents the publish git command
+var publishGitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Render posts into a git working tree and commit them",
+	Long: `Fetch posts like the fetch command, render them through the template into a
+file in a git working tree, such as a Hugo site, and commit the file. The file
+path and commit message are templates over the same data as the output, e.g.
+"content/posts/mastodon-{{.EndDate}}.md".
+
+Refuses to run when the working tree has uncommitted changes other than to the
+file itself, so unrelated work never ends up in the commit. Nothing is committed
+if the rendered file is unchanged.
+
+Example usage:
+  mastodon-to-markdown publish git --since 7d --repo ~/blog --path "content/posts/mastodon-{{.EndDate}}.md"
+  mastodon-to-markdown publish git --since 7d --repo ~/blog --path digest.md --push`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindPipelineFlags(cmd)
+		bindPublishFlags(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		ctx := context.Background()
+
+		repo := viper.GetString("publish.git.repo")
+		if repo == "" {
+			return fmt.Errorf("--repo is required")
+		}
+
+		data, err := fetchDigest(ctx)
+		if err != nil {
+			return err
+		}
+		rendered, err := renderDigest(data, viper.GetString("publish.template"))
+		if err != nil {
+			return err
+		}
+
+		path, err := templates.RenderString(viper.GetString("publish.git.path"), data)
+		if err != nil {
+			return fmt.Errorf("invalid --path: %w", err)
+		}
+		message, err := templates.RenderString(viper.GetString("publish.git.message"), data)
+		if err != nil {
+			return fmt.Errorf("invalid --message: %w", err)
+		}
+
+		git := &publish.Git{Dir: repo}
+		committed, err := git.Publish(ctx, publish.GitOptions{
+			Path:    path,
+			Content: rendered,
+			Message: message,
+			Push:    viper.GetBool("publish.git.push"),
+			Remote:  viper.GetString("publish.git.remote"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", repo, err)
+		}
+
+		if committed {
+			log.Infof("Committed %s in %s", path, repo)
+		} else {
+			log.Infof("%s in %s is unchanged, nothing to commit", path, repo)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+	publishCmd.AddCommand(publishGitCmd)
+
+	// Time range, filter, and sort flags
+	addPipelineFlags(publishGitCmd)
+
+	publishGitCmd.Flags().String("template", "", "Template: built-in name ('default', 'link-roundup') or path to a custom file (default: output.template)")
+	publishGitCmd.Flags().String("repo", "", "Path to the git working tree")
+	publishGitCmd.Flags().String("path", "mastodon-{{.EndDate}}.md", "File path in the working tree (a template)")
+	publishGitCmd.Flags().String("message", "Mastodon digest {{.StartDate}}–{{.EndDate}}", "Commit message (a template)")
+	publishGitCmd.Flags().Bool("push", false, "Push the commit")
+	publishGitCmd.Flags().String("remote", "origin", "Remote to push to")
+
+	_ = viper.BindPFlag("publish.git.repo", publishGitCmd.Flags().Lookup("repo"))
+	_ = viper.BindPFlag("publish.git.path", publishGitCmd.Flags().Lookup("path"))
+	_ = viper.BindPFlag("publish.git.message", publishGitCmd.Flags().Lookup("message"))
+	_ = viper.BindPFlag("publish.git.push", publishGitCmd.Flags().Lookup("push"))
+	_ = viper.BindPFlag("publish.git.remote", publishGitCmd.Flags().Lookup("remote"))
+}
+
+// bindPublishFlags binds the flags shared by publish commands to viper
+// Like bindPipelineFlags, this happens at run time since viper only keeps the
+// most recent binding for each key
+func bindPublishFlags(cmd *cobra.Command) {
+	_ = viper.BindPFlag("publish.template", cmd.Flags().Lookup("template"))
+}
+
+// fetchDigest fetches posts with the pipeline flags
+func fetchDigest(ctx context.Context) (*templates.TemplateData, error) {
+	opts, err := fetchOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := export.Fetch(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return export.NewTemplateData(opts.Start, opts.End, posts), nil
+}
+
+// renderDigest renders data through templatePath, or output.template if empty
+func renderDigest(data *templates.TemplateData, templatePath string) ([]byte, error) {
+	if templatePath == "" {
+		templatePath = viper.GetString("output.template")
+	}
+	renderer, err := export.NewRenderer(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render output: %w", err)
+	}
+	return buf.Bytes(), nil
+}
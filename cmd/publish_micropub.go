@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/publish"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// micropubNow returns the publish time of digest entries; tests replace it for stable output
+var micropubNow = time.Now
+
+// publishMicropubCmd represents the publish micropub command
+var publishMicropubCmd = &cobra.Command{
+	Use:   "micropub",
+	Short: "Post rendered posts to a Micropub endpoint",
+	Long: `Fetch posts like the fetch command and create entries at a Micropub endpoint,
+such as an IndieWeb blog.
+
+Modes:
+  digest  One entry with the rendered digest as content, titled with --name
+  posts   One entry per post of your own, published at the time of the post,
+          with content rendered from --post-content
+
+Hashtags become categories and image attachments become photos. The token is
+sent as a bearer token; set it with --token or publish.micropub.token in the
+config file.
+
+Example usage:
+  mastodon-to-markdown publish micropub --since 7d --endpoint https://blog.example/micropub
+  mastodon-to-markdown publish micropub --since 24h --mode posts --exclude-replies`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindPipelineFlags(cmd)
+		bindPublishFlags(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		ctx := context.Background()
+
+		endpoint := viper.GetString("publish.micropub.endpoint")
+		if endpoint == "" {
+			return fmt.Errorf("--endpoint is required")
+		}
+
+		var entries []publish.MicropubEntry
+		data, err := fetchDigest(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch mode := viper.GetString("publish.micropub.mode"); mode {
+		case "digest":
+			entry, err := micropubDigestEntry(data)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		case "posts":
+			if entries, err = micropubPostEntries(data); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown micropub mode %q (expected digest or posts)", mode)
+		}
+
+		client := publish.NewMicropub(endpoint, viper.GetString("publish.micropub.token"))
+		for _, entry := range entries {
+			location, err := client.Create(ctx, entry)
+			if err != nil {
+				return err
+			}
+			if location != "" {
+				log.Infof("Created %s", location)
+			} else {
+				log.Info("Created entry")
+			}
+		}
+
+		log.Infof("Published %d entries to %s", len(entries), endpoint)
+		return nil
+	},
+}
+
+func init() {
+	publishCmd.AddCommand(publishMicropubCmd)
+
+	// Time range, filter, and sort flags
+	addPipelineFlags(publishMicropubCmd)
+
+	publishMicropubCmd.Flags().String("endpoint", "", "Micropub endpoint URL")
+	publishMicropubCmd.Flags().String("token", "", "Micropub access token (default: publish.micropub.token)")
+	publishMicropubCmd.Flags().String("mode", "digest", "'digest' (one entry for all posts) or 'posts' (one entry per post of your own)")
+	publishMicropubCmd.Flags().String("template", "", "Template for the digest: built-in name or path to a custom file (default: output.template)")
+	publishMicropubCmd.Flags().String("name", "Mastodon digest {{.StartDate}}–{{.EndDate}}", "Digest entry title (a template)")
+	publishMicropubCmd.Flags().String("post-content", "{{if .ContentWarning}}CW: {{.ContentWarning}}\n\n{{end}}{{.Content}}", "Content of each entry in posts mode (a template over the post)")
+
+	_ = viper.BindPFlag("publish.micropub.endpoint", publishMicropubCmd.Flags().Lookup("endpoint"))
+	_ = viper.BindPFlag("publish.micropub.token", publishMicropubCmd.Flags().Lookup("token"))
+	_ = viper.BindPFlag("publish.micropub.mode", publishMicropubCmd.Flags().Lookup("mode"))
+	_ = viper.BindPFlag("publish.micropub.name", publishMicropubCmd.Flags().Lookup("name"))
+	_ = viper.BindPFlag("publish.micropub.post_content", publishMicropubCmd.Flags().Lookup("post-content"))
+}
+
+// micropubDigestEntry renders the whole digest as one entry, published now
+func micropubDigestEntry(data *templates.TemplateData) (publish.MicropubEntry, error) {
+	content, err := renderDigest(data, viper.GetString("publish.template"))
+	if err != nil {
+		return publish.MicropubEntry{}, err
+	}
+	name, err := templates.RenderString(viper.GetString("publish.micropub.name"), data)
+	if err != nil {
+		return publish.MicropubEntry{}, fmt.Errorf("invalid --name: %w", err)
+	}
+	return publish.DigestEntry(name, content, micropubNow(), data.Posts), nil
+}
+
+// micropubPostEntries renders an entry for each post of my own, skipping
+// boosts and favorites since they were written by someone else
+func micropubPostEntries(data *templates.TemplateData) ([]publish.MicropubEntry, error) {
+	entries := []publish.MicropubEntry{}
+	for _, post := range data.Posts {
+		if post.IsBoost || post.IsFavorited || post.Author != nil {
+			continue
+		}
+		content, err := templates.RenderString(viper.GetString("publish.micropub.post_content"), post)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --post-content: %w", err)
+		}
+		entries = append(entries, publish.PostEntry(post, []byte(content)))
+	}
+	return entries, nil
+}
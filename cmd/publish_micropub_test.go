@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startMicropubServer records form-encoded create requests, one line per field
+func startMicropubServer(t *testing.T) (*httptest.Server, func() string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var log strings.Builder
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		keys := []string{}
+		for key := range r.PostForm {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		log.WriteString("--- entry\n")
+		for _, key := range keys {
+			for _, value := range r.PostForm[key] {
+				fmt.Fprintf(&log, "%s: %q\n", key, value)
+			}
+		}
+
+		w.Header().Set("Location", "https://blog.example/entries/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return log.String()
+	}
+}
+
+func TestPublishMicropubGolden(t *testing.T) {
+	server := startFakeServer(t)
+	micropubNow = func() time.Time { return time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { micropubNow = time.Now })
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "digest.txt", args: []string{"--template", "link-roundup"}},
+		{name: "posts.txt", args: []string{"--mode", "posts", "--exclude-replies"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			micropub, requests := startMicropubServer(t)
+			args := append([]string{"publish", "micropub", "--start", "2025-11-03", "--end", "2025-11-10",
+				"--endpoint", micropub.URL, "--token", "secret"}, tt.args...)
+			runCommand(t, server.URL, args...)
+			assertGolden(t, "micropub-"+tt.name, []byte(requests()))
+		})
+	}
+}
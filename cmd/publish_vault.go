@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/publish"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// publishVaultCmd represents the publish vault command
+var publishVaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Write each day's posts into daily notes in an Obsidian or Logseq vault",
+	Long: `Fetch posts like the fetch command and write each day's posts into that day's
+note in a notes vault, such as Obsidian's daily notes or Logseq's journals.
+
+The posts go between <!-- mastodon:start --> and <!-- mastodon:end --> markers,
+added to the end of the note the first time. Everything outside the markers is
+left alone, so re-running only rewrites the posts. Notes that don't exist yet are
+created, and days without posts are skipped.
+
+The built-in daily-note template links people and hashtags as [[wikilinks]]; use
+the wikilink and wikify functions in custom templates to do the same.
+
+Example usage:
+  mastodon-to-markdown publish vault --since 7d --dir ~/Notes/Daily
+  mastodon-to-markdown publish vault --since 24h --dir ~/Logseq/journals --date-format 2006_01_02`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindPipelineFlags(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		ctx := context.Background()
+
+		dir := viper.GetString("publish.vault.dir")
+		if dir == "" {
+			return fmt.Errorf("--dir is required")
+		}
+
+		renderer, err := export.NewRenderer(viper.GetString("publish.vault.template"))
+		if err != nil {
+			return fmt.Errorf("failed to initialize template: %w", err)
+		}
+
+		data, err := fetchDigest(ctx)
+		if err != nil {
+			return err
+		}
+
+		vault := &publish.Vault{Dir: dir, DateFormat: viper.GetString("publish.vault.date_format")}
+		updated := 0
+		for _, day := range data.Days {
+			dayData, err := vaultDayData(data.Posts, day.Date)
+			if err != nil {
+				return err
+			}
+
+			var buf bytes.Buffer
+			if err := renderer.Render(&buf, dayData); err != nil {
+				return fmt.Errorf("failed to render output: %w", err)
+			}
+
+			changed, err := vault.Publish(day.Date, buf.Bytes())
+			if err != nil {
+				return err
+			}
+			path, _ := vault.NotePath(day.Date)
+			if changed {
+				log.Infof("Updated %s", path)
+				updated++
+			} else {
+				log.Debugf("%s is unchanged", path)
+			}
+		}
+
+		log.Infof("Updated %d of %d daily notes in %s", updated, len(data.Days), dir)
+		return nil
+	},
+}
+
+func init() {
+	publishCmd.AddCommand(publishVaultCmd)
+
+	// Time range, filter, and sort flags
+	addPipelineFlags(publishVaultCmd)
+
+	publishVaultCmd.Flags().String("dir", "", "Folder of daily notes")
+	publishVaultCmd.Flags().String("date-format", "2006-01-02", "Daily note file name, as a Go time layout (e.g. '2006_01_02' for Logseq)")
+	publishVaultCmd.Flags().String("template", "daily-note", "Template for each day: built-in name or path to a custom file")
+
+	_ = viper.BindPFlag("publish.vault.dir", publishVaultCmd.Flags().Lookup("dir"))
+	_ = viper.BindPFlag("publish.vault.date_format", publishVaultCmd.Flags().Lookup("date-format"))
+	_ = viper.BindPFlag("publish.vault.template", publishVaultCmd.Flags().Lookup("template"))
+}
+
+// vaultDayData returns template data for the posts on one day, in their original order
+func vaultDayData(posts []templates.Post, date string) (*templates.TemplateData, error) {
+	start, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+
+	var dayPosts []templates.Post
+	for _, post := range posts {
+		if post.FormattedDate == date {
+			dayPosts = append(dayPosts, post)
+		}
+	}
+	return export.NewTemplateData(start, start.AddDate(0, 0, 1), dayPosts), nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPublishVaultGolden(t *testing.T) {
+	server := startFakeServer(t)
+	dir := t.TempDir()
+
+	// A note written by hand before publishing
+	note := filepath.Join(dir, "2025-11-08.md")
+	if err := os.WriteFile(note, []byte("# Saturday\n\nHand-written notes.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"publish", "vault", "--start", "2025-11-03", "--end", "2025-11-10", "--dir", dir}
+	runCommand(t, server.URL, args...)
+
+	// More hand-written text below the region survives publishing again
+	got, err := os.ReadFile(note)
+	if err != nil {
+		t.Fatalf("failed to read note: %v", err)
+	}
+	if err := os.WriteFile(note, append(got, []byte("\nEvening: more notes.\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runCommand(t, server.URL, append(args, "--public-only=false")...)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []byte
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, []byte("=== "+filepath.Base(file)+"\n")...)
+		all = append(all, content...)
+	}
+	assertGolden(t, "vault-notes.md", all)
+}
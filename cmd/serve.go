@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/fakeserver"
+	"github.com/lmorchard/mastodon-to-markdown/internal/pipeline"
+	"github.com/lmorchard/mastodon-to-markdown/internal/preview"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Preview a template in the browser with live reload",
+	Long: `Start a local web server that renders the current template, as HTML at /
+and as raw Markdown at /raw. The page reloads whenever the template file or
+the config file changes.
+
+Posts come from template data cached with 'fetch --format json', given by
+--data, or else from the built-in fixtures (or a --fixtures directory, as for
+'dev fake-server'), so no Mastodon account is needed.
+
+Query parameters override the time range, filters, and sort order, using
+the same names as the fetch flags: since, start, end, sort-order, public-only,
+exclude-replies, exclude-boosts, exclude-favorites, and visibility.
+
+Example usage:
+  mastodon-to-markdown serve --template mastodon-to-markdown.md
+  mastodon-to-markdown fetch --since 7d --format json --output week.json
+  mastodon-to-markdown serve --data week.json --template link-roundup
+  open "http://127.0.0.1:8080/?exclude-boosts&sort-order=desc"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		addr := viper.GetString("serve.addr")
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		data, err := loadPreviewData(ctx, viper.GetString("serve.data"), viper.GetString("serve.fixtures"))
+		if err != nil {
+			return err
+		}
+
+		previewServer := preview.New(data, previewSettings())
+
+		// Watch the template and config for changes
+		configFile := viper.ConfigFileUsed()
+		go func() {
+			err := previewWatch(ctx, configFile, func() {
+				if configFile != "" {
+					if err := viper.ReadInConfig(); err != nil {
+						log.Warnf("Failed to reload %s: %v", configFile, err)
+					}
+				}
+				log.Info("Reloading preview")
+				previewServer.Update(previewSettings())
+			})
+			if err != nil {
+				log.Warnf("Live reload stopped: %v", err)
+			}
+		}()
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+
+		server := &http.Server{
+			Handler:           previewServer,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Previewing on http://%s\n", listener.Addr())
+
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("preview server failed: %w", err)
+		}
+
+		log.Info("Preview server stopped")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().String("data", "", "Template data written by 'fetch --format json' (default: fixtures)")
+	serveCmd.Flags().String("fixtures", "", "Directory of fixture JSON files (default: built-in fixtures)")
+	serveCmd.Flags().String("template", "", "Template: built-in name ('default', 'link-roundup', 'about', 'org', 'gemtext') or path to a custom file")
+
+	_ = viper.BindPFlag("serve.addr", serveCmd.Flags().Lookup("addr"))
+	_ = viper.BindPFlag("serve.data", serveCmd.Flags().Lookup("data"))
+	_ = viper.BindPFlag("serve.fixtures", serveCmd.Flags().Lookup("fixtures"))
+	_ = viper.BindPFlag("serve.template", serveCmd.Flags().Lookup("template"))
+}
+
+// previewSettings reads the template and default filters from flags and config
+// The time range defaults to all of the data; use query parameters to narrow it
+func previewSettings() preview.Settings {
+	template := viper.GetString("serve.template")
+	if template == "" {
+		template = viper.GetString("output.template")
+	}
+
+	return preview.Settings{
+		Template: template,
+		Options: pipeline.Options{
+			ExcludeReplies:   viper.GetBool("fetch.exclude_replies"),
+			ExcludeBoosts:    viper.GetBool("fetch.exclude_boosts"),
+			ExcludeFavorites: viper.GetBool("fetch.exclude_favorites"),
+			Visibility:       viper.GetString("fetch.visibility"),
+			PublicOnly:       viper.GetBool("output.public_only"),
+			SortOrder:        viper.GetString("output.sort_order"),
+		},
+	}
+}
+
+// previewWatch watches the current template, if it's a file, and the config file
+// The template is looked up again after each change, since the config may name another
+func previewWatch(ctx context.Context, configFile string, onChange func()) error {
+	for {
+		files := []string{}
+		if configFile != "" {
+			files = append(files, configFile)
+		}
+		template := previewSettings().Template
+		if _, err := os.Stat(template); err == nil {
+			files = append(files, template)
+		}
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		changed := false
+		err := preview.Watch(watchCtx, files, func() {
+			onChange()
+			if previewSettings().Template != template {
+				// Start over to watch the new template
+				changed = true
+				cancel()
+			}
+		})
+		cancel()
+		if err != nil || !changed {
+			return err
+		}
+	}
+}
+
+// loadPreviewData reads cached template data, or fetches every post from fixtures
+func loadPreviewData(ctx context.Context, dataFile, fixturesDir string) (*templates.TemplateData, error) {
+	if dataFile != "" {
+		f, err := os.Open(dataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open data file %s: %w", dataFile, err)
+		}
+		defer f.Close()
+		return templates.ReadData(f)
+	}
+
+	fsys := fakeserver.DefaultFixtures()
+	if fixturesDir != "" {
+		fsys = os.DirFS(fixturesDir)
+	}
+	fake, err := fakeserver.New(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fixtures: %w", err)
+	}
+
+	// Fetch through a private fake server, the same way fetch would
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start fixture server: %w", err)
+	}
+	server := &http.Server{Handler: fake, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	opts := export.FetchOptions{
+		Server:      "http://" + listener.Addr().String(),
+		AccessToken: "fake",
+		Start:       time.Unix(0, 0),
+		End:         time.Now(),
+		EmojiMode:   viper.GetString("output.emoji"),
+		Log:         GetLogger(),
+	}
+	if opts.Source, err = export.OpenSource(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	// Keep every post, so query parameters decide what to show
+	posts, err := export.Fetch(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	data := export.NewTemplateData(postsStart(posts), opts.End, posts)
+
+	about, err := export.FetchAbout(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	about.AddTo(data)
+
+	return data, nil
+}
+
+// postsStart returns the time of the earliest post, or now if there are none
+func postsStart(posts []templates.Post) time.Time {
+	start := time.Now()
+	for _, post := range posts {
+		if post.CreatedAt.Before(start) {
+			start = post.CreatedAt
+		}
+	}
+	return start
+}
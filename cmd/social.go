@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/config"
+	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+	"github.com/lmorchard/mastodon-to-markdown/internal/social"
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// socialNow returns the current time; tests replace it for stable output
+var socialNow = time.Now
+
+// socialCmd groups commands that work with followers and following
+var socialCmd = &cobra.Command{
+	Use:   "social",
+	Short: "Work with your followers and following",
+}
+
+// socialExportCmd represents the social export command
+var socialExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export followers and following as CSV, JSON, or Markdown",
+	Long: `Page through the followers and following of the authenticated account and
+export them with acct, display name, URL, note, bot flag, and last post date.
+
+The Mastodon API doesn't say when a follow happened, so with --archive a local
+JSON file records the date each account was first seen in each list. Every run
+adds newly seen accounts and forgets departed ones, and the "followed since"
+column is filled in from it. Accounts already in a list on the first run that
+records it are shown as followed "before" that date. Run it regularly (or start from an
+older archive) for useful dates.
+
+Output formats:
+  csv       One row per account, with a list column (default)
+  json      Machine-readable JSON
+  markdown  Tables rendered through a template (built-in, or --template)
+
+Example usage:
+  mastodon-to-markdown social export --output social.csv
+  mastodon-to-markdown social export --list following --format markdown --archive social-archive.json
+  mastodon-to-markdown social export --format json --output social.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+
+		log.Info("Running social export command")
+
+		format := viper.GetString("social.format")
+		if format != "csv" && format != "json" && format != "markdown" {
+			return fmt.Errorf("unknown social format %q (expected csv, json, or markdown)", format)
+		}
+
+		list := viper.GetString("social.list")
+		if list != "both" && list != social.Followers && list != social.Following {
+			return fmt.Errorf("unknown list %q (expected both, followers, or following)", list)
+		}
+
+		cfg := &config.Config{}
+		cfg.Mastodon.Server = viper.GetString("mastodon.server")
+		cfg.Mastodon.AccessToken = viper.GetString("mastodon.access_token")
+		client, err := mastodon.NewClient(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create Mastodon client: %w", err)
+		}
+
+		ctx := context.Background()
+		account, err := client.VerifyCredentials(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to verify Mastodon credentials: %w", err)
+		}
+
+		var archive *social.Archive
+		archivePath := viper.GetString("social.archive")
+		if archivePath != "" {
+			if archive, err = social.LoadArchive(archivePath); err != nil {
+				return err
+			}
+		}
+
+		today := timerange.FormatDate(socialNow())
+		report := &social.Report{Account: account.Acct, Date: today}
+
+		if list == "both" || list == social.Followers {
+			log.Info("Fetching followers...")
+			followers, err := client.GetFollowers(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+			log.Infof("Found %d followers", len(followers))
+			var since map[string]string
+			if archive != nil {
+				archive.Record(social.Followers, followers, today)
+				since = archive.Since(social.Followers)
+			}
+			report.Followers = social.NewEntries(followers, since)
+		}
+
+		if list == "both" || list == social.Following {
+			log.Info("Fetching following...")
+			following, err := client.GetFollowing(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+			log.Infof("Found %d followed accounts", len(following))
+			var since map[string]string
+			if archive != nil {
+				archive.Record(social.Following, following, today)
+				since = archive.Since(social.Following)
+			}
+			report.Following = social.NewEntries(following, since)
+		}
+
+		// Save the archive only once the report is written, so a failed run
+		// doesn't leave accounts recorded that were never reported
+		outputFile := viper.GetString("social.output")
+		err = writeOutput(outputFile, func(w io.Writer) error {
+			switch format {
+			case "json":
+				return social.WriteJSON(w, report)
+			case "markdown":
+				return social.WriteMarkdown(w, report, viper.GetString("social.template"))
+			default:
+				return social.WriteCSV(w, report)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write social export: %w", err)
+		}
+
+		if archive != nil {
+			if err := archive.Save(archivePath); err != nil {
+				return err
+			}
+		}
+
+		if outputFile != "" && outputFile != "-" {
+			log.Infof("Social export written to %s", outputFile)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(socialCmd)
+	socialCmd.AddCommand(socialExportCmd)
+
+	socialExportCmd.Flags().String("list", "both", "Which list to export: 'followers', 'following', or 'both'")
+	socialExportCmd.Flags().String("format", "csv", "Output format: 'csv', 'json', or 'markdown'")
+	socialExportCmd.Flags().String("template", "", "Custom template file for markdown format (default: built-in)")
+	socialExportCmd.Flags().String("archive", "", "Local archive of first-seen follow dates to read and update")
+	socialExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	// Bind flags to viper
+	_ = viper.BindPFlag("social.list", socialExportCmd.Flags().Lookup("list"))
+	_ = viper.BindPFlag("social.format", socialExportCmd.Flags().Lookup("format"))
+	_ = viper.BindPFlag("social.template", socialExportCmd.Flags().Lookup("template"))
+	_ = viper.BindPFlag("social.archive", socialExportCmd.Flags().Lookup("archive"))
+	_ = viper.BindPFlag("social.output", socialExportCmd.Flags().Lookup("output"))
+}
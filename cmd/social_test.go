@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSocialExportGolden(t *testing.T) {
+	server := startFakeServer(t)
+
+	socialNow = func() time.Time { return time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { socialNow = time.Now })
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "default.csv"},
+		{name: "format-json.json", args: []string{"--format", "json"}},
+		{name: "following-markdown.md", args: []string{"--list", "following", "--format", "markdown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "archive.json")
+			seed, err := os.ReadFile(filepath.Join("testdata", "social-archive.json"))
+			if err != nil {
+				t.Fatalf("failed to read archive: %v", err)
+			}
+			if err := os.WriteFile(archive, seed, 0o644); err != nil {
+				t.Fatalf("failed to write archive: %v", err)
+			}
+
+			output := filepath.Join(dir, tt.name)
+			args := append([]string{"social", "export", "--archive", archive, "--output", output}, tt.args...)
+			runCommand(t, server.URL, args...)
+
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			assertGolden(t, "social-"+tt.name, got)
+
+			if tt.name == "default.csv" {
+				updated, err := os.ReadFile(archive)
+				if err != nil {
+					t.Fatalf("failed to read updated archive: %v", err)
+				}
+				assertGolden(t, "social-archive-updated.json", updated)
+			}
+		})
+	}
+}
+
+func TestSocialExportNewArchive(t *testing.T) {
+	server := startFakeServer(t)
+
+	socialNow = func() time.Time { return time.Date(2025, 11, 10, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { socialNow = time.Now })
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive.json")
+	output := filepath.Join(dir, "social.md")
+	runCommand(t, server.URL, "social", "export", "--archive", archive, "--format", "markdown", "--output", output)
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	assertGolden(t, "social-new-archive.md", got)
+
+	created, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatalf("failed to read created archive: %v", err)
+	}
+	assertGolden(t, "social-archive-new.json", created)
+}
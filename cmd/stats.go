@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/stats"
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report engagement statistics for a time period",
+	Long: `Fetch posts for a time period using the same pipeline and filters as fetch,
+then report post counts by type, engagement received, top posts, most used
+hashtags, most boosted and favorited accounts, and a posting-hour heatmap.
+
+Output formats:
+  table     Plain-text tables for the terminal (default)
+  json      Machine-readable JSON
+  markdown  Rendered through a template (built-in, or --template for a custom file)
+
+Example usage:
+  mastodon-to-markdown stats --since 30d
+  mastodon-to-markdown stats --start 2025-11-01 --end 2025-12-01 --format markdown --output stats.md
+  mastodon-to-markdown stats --since 7d --format json`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindPipelineFlags(cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+
+		log.Info("Running stats command")
+
+		format := viper.GetString("stats.format")
+		if format != "table" && format != "json" && format != "markdown" {
+			return fmt.Errorf("unknown stats format %q (expected table, json, or markdown)", format)
+		}
+		if top := viper.GetInt("stats.top"); top < 0 {
+			return fmt.Errorf("--top must be zero or more, got %d", top)
+		}
+
+		opts, err := fetchOptions()
+		if err != nil {
+			return err
+		}
+
+		posts, err := export.Fetch(context.Background(), opts)
+		if err != nil {
+			return err
+		}
+
+		report := stats.Compute(
+			timerange.FormatDate(opts.Start),
+			timerange.FormatDate(opts.End),
+			posts,
+			viper.GetInt("stats.top"),
+		)
+
+		// Write to file or stdout
+		var w io.Writer = os.Stdout
+		outputFile := viper.GetString("stats.output")
+		if outputFile != "" && outputFile != "-" {
+			f, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to create output file %s: %w", outputFile, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		switch format {
+		case "json":
+			err = stats.WriteJSON(w, report)
+		case "markdown":
+			err = stats.WriteMarkdown(w, report, viper.GetString("stats.template"))
+		default:
+			err = stats.WriteTable(w, report)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write stats: %w", err)
+		}
+
+		if outputFile != "" && outputFile != "-" {
+			log.Infof("Stats written to %s", outputFile)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	// Time range, filter, and sort flags
+	addPipelineFlags(statsCmd)
+
+	// Report flags
+	statsCmd.Flags().String("format", "table", "Output format: 'table', 'json', or 'markdown'")
+	statsCmd.Flags().String("template", "", "Custom template file for markdown format (default: built-in)")
+	statsCmd.Flags().Int("top", 5, "Number of entries in top posts, hashtags, and accounts lists")
+	statsCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	// Bind flags to viper
+	_ = viper.BindPFlag("stats.format", statsCmd.Flags().Lookup("format"))
+	_ = viper.BindPFlag("stats.template", statsCmd.Flags().Lookup("template"))
+	_ = viper.BindPFlag("stats.top", statsCmd.Flags().Lookup("top"))
+	_ = viper.BindPFlag("stats.output", statsCmd.Flags().Lookup("output"))
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatsGolden(t *testing.T) {
+	server := startFakeServer(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "table.txt"},
+		{name: "json.json", args: []string{"--format", "json", "--top", "2"}},
+		{name: "markdown.md", args: []string{"--format", "markdown", "--public-only=false"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), tt.name)
+			args := append([]string{"stats", "--start", "2025-11-03", "--end", "2025-11-10", "--output", output}, tt.args...)
+			runCommand(t, server.URL, args...)
+
+			got, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			assertGolden(t, "stats-"+tt.name, got)
+		})
+	}
+}
+
+func TestStatsRejectsNegativeTop(t *testing.T) {
+	server := startFakeServer(t)
+
+	err := executeCommand(t, server.URL, "fake", "stats", "--since", "7d", "--top", "-1")
+	if err == nil || !strings.Contains(err.Error(), "--top") {
+		t.Errorf("expected a --top error, got %v", err)
+	}
+}
@@ -17,16 +17,20 @@ type Config struct {
 	Output struct {
 		IncludeMetadata  bool
 		IncludeMediaURLs bool
-		Template         string // Template to use: "default" (built-in) or path to custom file
+		Template         string // Template to use: built-in name ("default", "link-roundup", "about", "org", "gemtext") or path to custom file
 		SortOrder        string // "asc" (oldest first) or "desc" (newest first)
 		PublicOnly       bool   // Only include public posts (exclude direct/private)
+		Emoji            string // Custom emoji rendering: "shortcode", "image", or "drop"
 	}
 
 	// Fetch settings
 	Fetch struct {
-		ExcludeReplies    bool
-		ExcludeBoosts     bool
-		ExcludeFavorites  bool // Exclude favorited posts
-		Visibility        string
+		ExcludeReplies     bool
+		ExcludeBoosts      bool
+		ExcludeFavorites   bool  // Exclude favorited posts
+		IncludeEditHistory bool  // Fetch every version of edited posts
+		IncludePinned      bool  // Fetch the profile, pinned posts, and featured hashtags
+		MinEngagement      int64 // Minimum replies, boosts, and favourites combined
+		Visibility         string
 	}
 }
@@ -0,0 +1,320 @@
+// Package epub writes template data as an EPUB 3 ebook, one chapter per day
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// Book holds the ebook's metadata
+type Book struct {
+	Title      string
+	Author     string
+	Language   string // BCP 47 language tag, "en" if empty
+	Identifier string // Unique identifier, such as a URL or urn:uuid
+	Modified   time.Time
+}
+
+// Write packages data as an EPUB 3 file with a chapter per day group and a
+// generated table of contents
+// images maps media URLs to downloaded files to embed; other media is linked
+func Write(w io.Writer, book Book, data *templates.TemplateData, images map[string]string) error {
+	if book.Language == "" {
+		book.Language = "en"
+	}
+	modified := book.Modified.UTC().Truncate(time.Second)
+
+	b := &builder{
+		images:   map[string]string{},
+		modified: modified,
+	}
+
+	// Embedded images are named in order of first use, so output is stable
+	for _, day := range data.Days {
+		for _, post := range dayPosts(day) {
+			for _, url := range postImageURLs(post) {
+				file, ok := images[url]
+				if !ok || b.images[url] != "" {
+					continue
+				}
+				name := fmt.Sprintf("images/image-%03d%s", len(b.manifestImages)+1, strings.ToLower(filepath.Ext(file)))
+				b.images[url] = name
+				b.manifestImages = append(b.manifestImages, manifestImage{ID: fmt.Sprintf("image-%03d", len(b.manifestImages)+1), Href: name, File: file})
+			}
+		}
+	}
+
+	chapters := []chapter{}
+	for i, day := range data.Days {
+		content, err := b.chapter(book, day)
+		if err != nil {
+			return err
+		}
+		chapters = append(chapters, chapter{
+			ID:      fmt.Sprintf("day-%03d", i+1),
+			Href:    fmt.Sprintf("day-%03d.xhtml", i+1),
+			Title:   day.Date,
+			Content: content,
+		})
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype must come first and be stored uncompressed
+	if err := b.add(zw, "mimetype", []byte("application/epub+zip"), zip.Store); err != nil {
+		return err
+	}
+	if err := b.add(zw, "META-INF/container.xml", []byte(containerXML), zip.Deflate); err != nil {
+		return err
+	}
+
+	pkg := packageData{Book: book, Modified: modified.Format(time.RFC3339), Chapters: chapters, Images: b.manifestImages}
+	for _, image := range b.manifestImages {
+		mediaType := mime.TypeByExtension(path.Ext(image.Href))
+		if mediaType == "" {
+			mediaType = "application/octet-stream"
+		}
+		pkg.MediaTypes = append(pkg.MediaTypes, mediaType)
+	}
+	files := []struct {
+		name string
+		tmpl *template.Template
+		data any
+	}{
+		{"OEBPS/content.opf", packageTemplate, pkg},
+		{"OEBPS/nav.xhtml", navTemplate, pkg},
+	}
+	for _, file := range files {
+		var buf bytes.Buffer
+		if err := render(&buf, file.tmpl, file.data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", file.name, err)
+		}
+		if err := b.add(zw, file.name, buf.Bytes(), zip.Deflate); err != nil {
+			return err
+		}
+	}
+
+	if err := b.add(zw, "OEBPS/style.css", []byte(styleCSS), zip.Deflate); err != nil {
+		return err
+	}
+	for _, chapter := range chapters {
+		if err := b.add(zw, "OEBPS/"+chapter.Href, chapter.Content, zip.Deflate); err != nil {
+			return err
+		}
+	}
+	for _, image := range b.manifestImages {
+		content, err := os.ReadFile(image.File)
+		if err != nil {
+			return fmt.Errorf("failed to read image %s: %w", image.File, err)
+		}
+		// Images are already compressed
+		if err := b.add(zw, "OEBPS/"+image.Href, content, zip.Store); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write EPUB: %w", err)
+	}
+	return nil
+}
+
+// builder holds state shared while writing one book
+type builder struct {
+	images         map[string]string // Media URL to path in the book
+	manifestImages []manifestImage
+	modified       time.Time
+}
+
+// add writes a file to the archive
+func (b *builder) add(zw *zip.Writer, name string, content []byte, method uint16) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: b.modified})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to EPUB: %w", name, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		return fmt.Errorf("failed to add %s to EPUB: %w", name, err)
+	}
+	return nil
+}
+
+// chapter renders a day group as an XHTML document
+func (b *builder) chapter(book Book, day templates.DayGroup) ([]byte, error) {
+	data := chapterData{Book: book, Date: day.Date}
+	sections := []struct {
+		title string
+		posts []templates.Post
+	}{
+		{"Posts", day.OwnPosts},
+		{"Boosts", day.BoostedPosts},
+		{"Favorites", day.FavoritedPosts},
+	}
+	for _, section := range sections {
+		if len(section.posts) == 0 {
+			continue
+		}
+		s := chapterSection{Title: section.title}
+		for _, post := range section.posts {
+			s.Posts = append(s.Posts, b.post(post))
+		}
+		data.Sections = append(data.Sections, s)
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf, chapterTemplate, data); err != nil {
+		return nil, fmt.Errorf("failed to render chapter %s: %w", day.Date, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// post converts a post for the chapter template, using the original post's
+// content for boosts and favorites
+func (b *builder) post(post templates.Post) chapterPost {
+	p := chapterPost{Time: post.FormattedTimeOnly, URL: post.URL, ContentWarning: post.ContentWarning, Commentary: post.BoostCommentary}
+	content, links, emojis := post.Content, post.Links, post.Emojis
+	if post.Author != nil {
+		p.Author = fmt.Sprintf("%s (@%s)", post.Author.Name, post.Author.Acct)
+	}
+	if post.OriginalPost != nil && (post.IsBoost || post.IsFavorited) {
+		p.Author = fmt.Sprintf("%s (@%s)", post.OriginalPost.AuthorName, post.OriginalPost.AuthorAcct)
+		p.URL = post.OriginalPost.URL
+		p.ContentWarning = post.OriginalPost.ContentWarning
+		content, links, emojis = post.OriginalPost.Content, post.OriginalPost.Links, post.OriginalPost.Emojis
+	}
+
+	// Emoji images point at their embedded copies
+	body := templates.TextHTML(content, links, emojis)
+	for _, emoji := range emojis {
+		if src := b.images[emoji.URL]; src != "" {
+			body = strings.ReplaceAll(body, `src="`+html.EscapeString(emoji.URL)+`"`, `src="`+src+`"`)
+		}
+	}
+	p.Content = template.HTML(body)
+
+	for _, media := range postMedia(post) {
+		p.Media = append(p.Media, chapterMedia{
+			Type:        media.Type,
+			URL:         media.URL,
+			Src:         b.images[media.URL],
+			Description: media.Description,
+		})
+	}
+	return p
+}
+
+// dayPosts returns all of a day's posts
+func dayPosts(day templates.DayGroup) []templates.Post {
+	posts := append([]templates.Post{}, day.OwnPosts...)
+	posts = append(posts, day.BoostedPosts...)
+	return append(posts, day.FavoritedPosts...)
+}
+
+// postImageURLs returns the URLs of the images shown for a post: image
+// attachments, and custom emoji rendered as images in its content
+func postImageURLs(post templates.Post) []string {
+	var urls []string
+	for _, media := range postMedia(post) {
+		if media.Type == "image" {
+			urls = append(urls, media.URL)
+		}
+	}
+
+	content, emojis := post.Content, post.Emojis
+	if post.OriginalPost != nil && (post.IsBoost || post.IsFavorited) {
+		content, emojis = post.OriginalPost.Content, post.OriginalPost.Emojis
+	}
+	for _, emoji := range emojis {
+		if strings.Contains(content, templates.EmojiImage(emoji)) {
+			urls = append(urls, emoji.URL)
+		}
+	}
+	return urls
+}
+
+// postMedia returns the attachments shown for a post, the original post's for
+// boosts and favorites
+func postMedia(post templates.Post) []templates.MediaAttachment {
+	if post.OriginalPost != nil && (post.IsBoost || post.IsFavorited) {
+		return post.OriginalPost.MediaAttachments
+	}
+	return post.MediaAttachments
+}
+
+// ImageURLs returns the URLs of the image attachments and emoji images in
+// data, for downloading before calling Write
+func ImageURLs(data *templates.TemplateData) []string {
+	seen := map[string]bool{}
+	urls := []string{}
+	for _, day := range data.Days {
+		for _, post := range dayPosts(day) {
+			for _, url := range postImageURLs(post) {
+				if !seen[url] {
+					seen[url] = true
+					urls = append(urls, url)
+				}
+			}
+		}
+	}
+	return urls
+}
+
+type chapter struct {
+	ID      string
+	Href    string
+	Title   string
+	Content []byte
+}
+
+type manifestImage struct {
+	ID   string
+	Href string
+	File string // Local file to embed
+}
+
+type packageData struct {
+	Book       Book
+	Modified   string
+	Chapters   []chapter
+	Images     []manifestImage
+	MediaTypes []string // Media type of each image, by index
+}
+
+type chapterData struct {
+	Book     Book
+	Date     string
+	Sections []chapterSection
+}
+
+type chapterSection struct {
+	Title string
+	Posts []chapterPost
+}
+
+type chapterPost struct {
+	Time           string
+	Author         string // Empty for my own posts
+	URL            string
+	ContentWarning string
+	Commentary     string
+	Content        template.HTML
+	Media          []chapterMedia
+}
+
+type chapterMedia struct {
+	Type        string
+	URL         string
+	Src         string // Path in the book, empty if not embedded
+	Description string
+}
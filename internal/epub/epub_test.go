@@ -0,0 +1,119 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+func TestWrite(t *testing.T) {
+	image := filepath.Join(t.TempDir(), "sunset.png")
+	if err := os.WriteFile(image, []byte("\x89PNG fake"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	blobcat := filepath.Join(t.TempDir(), "blobcat.png")
+	if err := os.WriteFile(blobcat, []byte("\x89PNG blobcat"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := []templates.Post{
+		{
+			ID: "1", FormattedDate: "2025-11-03", FormattedTimeOnly: "09:00", URL: "https://example.social/@me/1",
+			Content:        "Fish & chips <3 at https://example.com/?a=1&b=2\n*not emphasis*",
+			ContentWarning: "food",
+			CreatedAt:      time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC),
+			MediaAttachments: []templates.MediaAttachment{
+				{Type: "image", URL: "https://example.social/sunset.png", Description: `"Sunset" & boats`},
+				{Type: "video", URL: "https://example.social/boats.mp4"},
+			},
+		},
+		{
+			ID: "2", FormattedDate: "2025-11-04", FormattedTimeOnly: "10:00", IsFavorited: true,
+			CreatedAt: time.Date(2025, 11, 4, 10, 0, 0, 0, time.UTC),
+			OriginalPost: &templates.OriginalPost{
+				AuthorName: "Bob", AuthorAcct: "bob@example.org", URL: "https://example.org/@bob/2",
+				Content:          "Same sunset ![:blobcat:](https://example.org/blobcat.png)",
+				Emojis:           []templates.Emoji{{Shortcode: "blobcat", URL: "https://example.org/blobcat.png"}},
+				MediaAttachments: []templates.MediaAttachment{{Type: "image", URL: "https://example.social/sunset.png"}},
+			},
+		},
+	}
+	data := &templates.TemplateData{Days: templates.GroupPostsByDay(posts)}
+
+	if got := ImageURLs(data); len(got) != 2 || got[0] != "https://example.social/sunset.png" || got[1] != "https://example.org/blobcat.png" {
+		t.Errorf("ImageURLs = %v, want the one image once and the emoji", got)
+	}
+
+	var buf bytes.Buffer
+	book := Book{Title: "Me & Mastodon", Author: "Me", Identifier: "urn:test", Modified: time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)}
+	if err := Write(&buf, book, data, map[string]string{"https://example.social/sunset.png": image, "https://example.org/blobcat.png": blobcat}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip file: %v", err)
+	}
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want an uncompressed mimetype", first.Name, first.Method)
+	}
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+
+		// Every document must be well-formed XML
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".xml") {
+			dec := xml.NewDecoder(bytes.NewReader(content))
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed: %v\n%s", f.Name, err, content)
+					break
+				}
+			}
+		}
+	}
+
+	if files["OEBPS/images/image-001.png"] != "\x89PNG fake" {
+		t.Errorf("image was not embedded, files: %v", keys(files))
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], `href="images/image-001.png" media-type="image/png"`) {
+		t.Errorf("image missing from manifest:\n%s", files["OEBPS/content.opf"])
+	}
+	day1 := files["OEBPS/day-001.xhtml"]
+	for _, want := range []string{`<img src="images/image-001.png" alt="&#34;Sunset&#34; &amp; boats"/>`, `<a href="https://example.social/boats.mp4">video</a>`, "CW: food", "Fish &amp; chips &lt;3", "<br />\n*not emphasis*</p>"} {
+		if !strings.Contains(day1, want) {
+			t.Errorf("day 1 is missing %s:\n%s", want, day1)
+		}
+	}
+	if day2 := files["OEBPS/day-002.xhtml"]; !strings.Contains(day2, "Bob (@bob@example.org)") || !strings.Contains(day2, `src="images/image-001.png"`) {
+		t.Errorf("day 2 should show the favorited post with the shared image:\n%s", day2)
+	}
+	if day2 := files["OEBPS/day-002.xhtml"]; !strings.Contains(day2, `Same sunset <img src="images/image-002.png" alt=":blobcat:" class="emoji" />`) {
+		t.Errorf("day 2 should embed the emoji image:\n%s", day2)
+	}
+}
+
+func keys(m map[string]string) []string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package epub
+
+import (
+	"encoding/xml"
+	"html/template"
+	"io"
+)
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const styleCSS = `body { font-family: serif; line-height: 1.4; }
+h1 { margin-bottom: 1em; }
+.post { margin-bottom: 1.5em; }
+.meta { font-size: 0.85em; color: #555; }
+.cw { font-weight: bold; }
+figure { margin: 1em 0; }
+img { max-width: 100%; }
+figcaption { font-size: 0.85em; font-style: italic; }
+`
+
+// The templates below are XHTML and XML, which html/template escapes correctly
+// as long as values only appear in text and attribute positions
+// They leave out the XML declaration, which html/template would escape; see render
+
+var packageTemplate = template.Must(template.New("content.opf").Parse(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{.Book.Language}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{.Book.Identifier}}</dc:identifier>
+    <dc:title>{{.Book.Title}}</dc:title>
+    <dc:creator>{{.Book.Author}}</dc:creator>
+    <dc:language>{{.Book.Language}}</dc:language>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{- range .Chapters}}
+    <item id="{{.ID}}" href="{{.Href}}" media-type="application/xhtml+xml"/>
+{{- end}}
+{{- range $i, $image := .Images}}
+    <item id="{{$image.ID}}" href="{{$image.Href}}" media-type="{{index $.MediaTypes $i}}"/>
+{{- end}}
+  </manifest>
+  <spine>
+    <itemref idref="nav"/>
+{{- range .Chapters}}
+    <itemref idref="{{.ID}}"/>
+{{- end}}
+  </spine>
+</package>
+`))
+
+var navTemplate = template.Must(template.New("nav.xhtml").Parse(`<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{.Book.Language}}" lang="{{.Book.Language}}">
+<head>
+  <meta charset="utf-8"/>
+  <title>{{.Book.Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <h1>{{.Book.Title}}</h1>
+  <p>{{.Book.Author}}</p>
+  <nav epub:type="toc" id="toc">
+    <h2>Contents</h2>
+    <ol>
+{{- range .Chapters}}
+      <li><a href="{{.Href}}">{{.Title}}</a></li>
+{{- end}}
+    </ol>
+  </nav>
+</body>
+</html>
+`))
+
+var chapterTemplate = template.Must(template.New("chapter").Parse(`<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{.Book.Language}}" lang="{{.Book.Language}}">
+<head>
+  <meta charset="utf-8"/>
+  <title>{{.Date}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <h1>{{.Date}}</h1>
+{{- range .Sections}}
+  <h2>{{.Title}}</h2>
+{{- range .Posts}}
+  <div class="post">
+    <p class="meta">{{.Time}}{{if .Author}} · {{.Author}}{{end}} · <a href="{{.URL}}">original</a></p>
+{{- if .Commentary}}
+    <p>{{.Commentary}}</p>
+{{- end}}
+{{- if .ContentWarning}}
+    <p class="cw">CW: {{.ContentWarning}}</p>
+{{- end}}
+    {{.Content}}
+{{- range .Media}}
+{{- if .Src}}
+    <figure>
+      <img src="{{.Src}}" alt="{{.Description}}"/>
+{{- if .Description}}
+      <figcaption>{{.Description}}</figcaption>
+{{- end}}
+    </figure>
+{{- else}}
+    <p><a href="{{.URL}}">{{.Type}}</a>{{if .Description}}: {{.Description}}{{end}}</p>
+{{- end}}
+{{- end}}
+  </div>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// render executes an XHTML or XML template, preceded by the XML declaration
+func render(w io.Writer, tmpl *template.Template, data any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
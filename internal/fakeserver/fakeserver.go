@@ -0,0 +1,428 @@
+// Package fakeserver implements a fake Mastodon API backed by fixture JSON,
+// for end-to-end tests and offline template development
+package fakeserver
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// DefaultFixtures returns the built-in fixture set
+func DefaultFixtures() fs.FS {
+	sub, _ := fs.Sub(fixtures, "fixtures")
+	return sub
+}
+
+// DefaultPageSize is the maximum number of items returned per page
+const DefaultPageSize = 40
+
+// Server is a fake Mastodon API serving fixture data
+//
+// Fixtures are read from a directory containing:
+//   - account.json: the authenticated account
+//   - statuses.json: the account's statuses, newest first
+//   - favourites.json: favourited statuses, newest first
+//   - histories.json: edit histories keyed by status ID (optional)
+//   - contexts.json: thread contexts keyed by status ID (optional)
+//   - lists.json: the account's lists (optional)
+//   - featured_tags.json: hashtags featured on the profile (optional)
+//   - followers.json, following.json: accounts, newest follow first (optional)
+//
+// Statuses with "pinned": true are served as the pinned statuses. The home
+// timeline is served from statuses and favourites, every list timeline from
+// favourites, and hashtag timelines from statuses and favourites with the tag
+type Server struct {
+	// PageSize caps the number of items per page, regardless of the requested limit
+	PageSize int
+
+	account    json.RawMessage
+	accountID  string
+	acct       string
+	domain     string
+	statuses   []item
+	favourites []item
+	histories  map[string]json.RawMessage
+	contexts   map[string]json.RawMessage
+	lists      json.RawMessage
+	listIDs    map[string]bool
+	featured   json.RawMessage
+	followers  []item
+	following  []item
+}
+
+// item is a fixture entry along with the fields used for pagination and filtering
+type item struct {
+	id        string
+	raw       json.RawMessage
+	createdAt string
+	tags      []string
+	reblog    bool
+	pinned    bool
+}
+
+// New loads fixtures from fsys and returns a server for them
+func New(fsys fs.FS) (*Server, error) {
+	s := &Server{
+		PageSize:  DefaultPageSize,
+		histories: map[string]json.RawMessage{},
+		contexts:  map[string]json.RawMessage{},
+	}
+
+	if err := readFixture(fsys, "account.json", &s.account); err != nil {
+		return nil, err
+	}
+	var account struct {
+		ID   string `json:"id"`
+		Acct string `json:"acct"`
+		URL  string `json:"url"`
+	}
+	if err := json.Unmarshal(s.account, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse account.json: %w", err)
+	}
+	s.accountID = account.ID
+	s.acct = account.Acct
+	if u, err := url.Parse(account.URL); err == nil {
+		s.domain = u.Host
+	}
+
+	var err error
+	if s.statuses, err = readItems(fsys, "statuses.json"); err != nil {
+		return nil, err
+	}
+	if s.favourites, err = readItems(fsys, "favourites.json"); err != nil {
+		return nil, err
+	}
+	if err := readOptionalFixture(fsys, "histories.json", &s.histories); err != nil {
+		return nil, err
+	}
+	if err := readOptionalFixture(fsys, "contexts.json", &s.contexts); err != nil {
+		return nil, err
+	}
+
+	if s.followers, err = readOptionalItems(fsys, "followers.json"); err != nil {
+		return nil, err
+	}
+	if s.following, err = readOptionalItems(fsys, "following.json"); err != nil {
+		return nil, err
+	}
+
+	s.featured = json.RawMessage("[]")
+	if err := readOptionalFixture(fsys, "featured_tags.json", &s.featured); err != nil {
+		return nil, err
+	}
+
+	s.lists = json.RawMessage("[]")
+	if err := readOptionalFixture(fsys, "lists.json", &s.lists); err != nil {
+		return nil, err
+	}
+	var lists []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(s.lists, &lists); err != nil {
+		return nil, fmt.Errorf("failed to parse lists.json: %w", err)
+	}
+	s.listIDs = map[string]bool{}
+	for _, list := range lists {
+		s.listIDs[list.ID] = true
+	}
+
+	return s, nil
+}
+
+// ServeHTTP routes requests to the fake API endpoints
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "api" || parts[1] != "v1" {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	route := parts[2:]
+
+	switch {
+	case match(route, "accounts", "verify_credentials"):
+		writeJSON(w, s.account)
+	case match(route, "accounts", "lookup"):
+		acct := strings.TrimPrefix(r.URL.Query().Get("acct"), "@")
+		if acct != s.acct && acct != s.acct+"@"+s.domain {
+			writeError(w, http.StatusNotFound, "Record not found")
+			return
+		}
+		writeJSON(w, s.account)
+	case match(route, "accounts", s.accountID):
+		writeJSON(w, s.account)
+	case match(route, "accounts", s.accountID, "statuses"):
+		if r.URL.Query().Get("pinned") == "true" {
+			s.writePage(w, r, s.pinned())
+			return
+		}
+		s.writePage(w, r, s.statuses)
+	case match(route, "accounts", s.accountID, "followers"):
+		s.writePage(w, r, s.followers)
+	case match(route, "accounts", s.accountID, "following"):
+		s.writePage(w, r, s.following)
+	case match(route, "accounts", s.accountID, "featured_tags"):
+		writeJSON(w, s.featured)
+	case match(route, "favourites"):
+		s.writePage(w, r, s.favourites)
+	case match(route, "lists"):
+		writeJSON(w, s.lists)
+	case match(route, "timelines", "home"):
+		s.writePage(w, r, s.home())
+	case len(route) == 3 && route[0] == "timelines" && route[1] == "list":
+		if !s.listIDs[route[2]] {
+			writeError(w, http.StatusNotFound, "Record not found")
+			return
+		}
+		s.writePage(w, r, s.favourites)
+	case len(route) == 3 && route[0] == "timelines" && route[1] == "tag":
+		s.writePage(w, r, s.tagged(route[2]))
+	case len(route) == 3 && route[0] == "statuses" && route[2] == "history":
+		s.writeKeyed(w, s.histories, route[1], "[]")
+	case len(route) == 3 && route[0] == "statuses" && route[2] == "context":
+		s.writeKeyed(w, s.contexts, route[1], `{"ancestors":[],"descendants":[]}`)
+	default:
+		writeError(w, http.StatusNotFound, "Record not found")
+	}
+}
+
+// writePage writes one page of items honoring max_id, since_id, min_id, and limit,
+// with a Link header pointing at the next and previous pages like Mastodon does
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, items []item) {
+	query := r.URL.Query()
+	maxID := query.Get("max_id")
+	sinceID := query.Get("since_id")
+	minID := query.Get("min_id")
+
+	limit := s.PageSize
+	if n, err := strconv.Atoi(query.Get("limit")); err == nil && n > 0 && n < limit {
+		limit = n
+	}
+
+	// Page past an item in the list by position, like Mastodon's opaque
+	// pagination, falling back to comparing IDs for unknown ones
+	for i, it := range items {
+		if it.id == maxID {
+			items = items[i+1:]
+			maxID = ""
+			break
+		}
+	}
+
+	var matched []item
+	for _, it := range items {
+		if maxID != "" && compareIDs(it.id, maxID) >= 0 {
+			continue
+		}
+		if sinceID != "" && compareIDs(it.id, sinceID) <= 0 {
+			continue
+		}
+		if minID != "" && compareIDs(it.id, minID) <= 0 {
+			continue
+		}
+		matched = append(matched, it)
+	}
+
+	// min_id pages forward from the given ID, so take the oldest matches
+	if minID != "" && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	} else if len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	if len(matched) > 0 {
+		next := pageURL(r, "max_id", matched[len(matched)-1].id)
+		prev := pageURL(r, "min_id", matched[0].id)
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="prev"`, next, prev))
+	}
+
+	raws := make([]json.RawMessage, 0, len(matched))
+	for _, it := range matched {
+		raws = append(raws, it.raw)
+	}
+	writeJSON(w, raws)
+}
+
+// pinned returns statuses pinned to the profile
+func (s *Server) pinned() []item {
+	var items []item
+	for _, it := range s.statuses {
+		if it.pinned {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+// home returns statuses and favourites, newest first
+func (s *Server) home() []item {
+	return s.merged(func(it item) bool { return true })
+}
+
+// tagged returns statuses and favourites with a hashtag, newest first
+// Like the real tag timeline, it leaves out boosts
+func (s *Server) tagged(tag string) []item {
+	tag = strings.ToLower(tag)
+	return s.merged(func(it item) bool {
+		if it.reblog {
+			return false
+		}
+		for _, t := range it.tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// merged returns statuses and favourites that match keep, newest first by
+// creation time, since fixture IDs of different accounts aren't comparable
+func (s *Server) merged(keep func(it item) bool) []item {
+	seen := map[string]bool{}
+	var items []item
+	for _, it := range append(append([]item{}, s.statuses...), s.favourites...) {
+		if seen[it.id] || !keep(it) {
+			continue
+		}
+		seen[it.id] = true
+		items = append(items, it)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].createdAt > items[j].createdAt
+	})
+	return items
+}
+
+// writeKeyed writes the fixture stored under id, or fallback when there is none
+func (s *Server) writeKeyed(w http.ResponseWriter, fixtures map[string]json.RawMessage, id, fallback string) {
+	if raw, ok := fixtures[id]; ok {
+		writeJSON(w, raw)
+		return
+	}
+	writeJSON(w, json.RawMessage(fallback))
+}
+
+// pageURL rebuilds the request URL with a single pagination parameter replaced
+func pageURL(r *http.Request, param, id string) string {
+	query := r.URL.Query()
+	query.Del("max_id")
+	query.Del("since_id")
+	query.Del("min_id")
+	query.Set(param, id)
+	u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// compareIDs compares Mastodon's numeric string IDs without overflowing
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+// match reports whether a route has exactly the given segments
+func match(route []string, segments ...string) bool {
+	if len(route) != len(segments) {
+		return false
+	}
+	for i := range route {
+		if route[i] != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a Mastodon-style JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// readFixture decodes a required fixture file
+func readFixture(fsys fs.FS, name string, v any) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+// readOptionalFixture decodes a fixture file, leaving v untouched if it doesn't exist
+func readOptionalFixture(fsys fs.FS, name string, v any) error {
+	if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return readFixture(fsys, name, v)
+}
+
+// readOptionalItems is readItems, returning no items if the file doesn't exist
+func readOptionalItems(fsys fs.FS, name string) ([]item, error) {
+	if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return readItems(fsys, name)
+}
+
+// readItems decodes a fixture array and sorts it newest first by ID
+func readItems(fsys fs.FS, name string) ([]item, error) {
+	var raws []json.RawMessage
+	if err := readFixture(fsys, name, &raws); err != nil {
+		return nil, err
+	}
+
+	items := make([]item, 0, len(raws))
+	for _, raw := range raws {
+		var entry struct {
+			ID        string `json:"id"`
+			CreatedAt string `json:"created_at"`
+			Tags      []struct {
+				Name string `json:"name"`
+			} `json:"tags"`
+			Reblog json.RawMessage `json:"reblog"`
+			Pinned bool            `json:"pinned"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse entry in fixture %s: %w", name, err)
+		}
+		it := item{id: entry.ID, raw: raw, createdAt: entry.CreatedAt, pinned: entry.Pinned, reblog: len(entry.Reblog) > 0 && string(entry.Reblog) != "null"}
+		for _, tag := range entry.Tags {
+			it.tags = append(it.tags, strings.ToLower(tag.Name))
+		}
+		items = append(items, it)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return compareIDs(items[i].id, items[j].id) > 0
+	})
+
+	return items, nil
+}
@@ -0,0 +1,95 @@
+// Package journal tracks which posts have already been written to a running
+// journal file, so repeated fetches only append new ones
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// State records the posts already written, keyed by status ID
+// Favorites are keyed separately, since favoriting one of my own posts makes it
+// appear twice with the same ID
+type State struct {
+	Seen    map[string]string `json:"seen"`               // Post key to the time it was written
+	LastDay string            `json:"last_day,omitempty"` // Date of the last day written, whose heading is already in the journal
+}
+
+// LoadState reads a state file, returning an empty state if the file doesn't exist
+func LoadState(path string) (*State, error) {
+	state := &State{Seen: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	if state.Seen == nil {
+		state.Seen = map[string]string{}
+	}
+	return state, nil
+}
+
+// Unseen returns the posts not recorded yet, in their original order
+func (s *State) Unseen(posts []templates.Post) []templates.Post {
+	unseen := []templates.Post{}
+	for _, post := range posts {
+		if _, ok := s.Seen[key(post)]; !ok {
+			unseen = append(unseen, post)
+		}
+	}
+	return unseen
+}
+
+// Record marks posts as written at the given time
+func (s *State) Record(posts []templates.Post, at string) {
+	for _, post := range posts {
+		s.Seen[key(post)] = at
+	}
+}
+
+// Save writes the state as indented JSON, replacing the file in one step so an
+// interrupted save can't lose the posts already recorded
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state %s: %w", path, err)
+	}
+	return nil
+}
+
+// key identifies a post in the state
+func key(post templates.Post) string {
+	if post.IsFavorited {
+		return "favourite:" + post.ID
+	}
+	return post.ID
+}
@@ -1,8 +1,13 @@
 package mastodon
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/lmorchard/mastodon-to-markdown/internal/config"
 	"github.com/mattn/go-mastodon"
@@ -23,6 +28,19 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		return nil, fmt.Errorf("mastodon access token is required")
 	}
 
+	return newClient(cfg), nil
+}
+
+// NewPublicClient creates a Mastodon API client for endpoints that don't
+// require authentication; the access token is still sent if configured
+func NewPublicClient(cfg *config.Config) (*Client, error) {
+	if cfg.Mastodon.Server == "" {
+		return nil, fmt.Errorf("mastodon server URL is required")
+	}
+	return newClient(cfg), nil
+}
+
+func newClient(cfg *config.Config) *Client {
 	client := mastodon.NewClient(&mastodon.Config{
 		Server:      cfg.Mastodon.Server,
 		AccessToken: cfg.Mastodon.AccessToken,
@@ -31,7 +49,7 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	return &Client{
 		client: client,
 		config: cfg,
-	}, nil
+	}
 }
 
 // VerifyCredentials checks if the access token is valid and returns account info
@@ -43,6 +61,23 @@ func (c *Client) VerifyCredentials(ctx context.Context) (*mastodon.Account, erro
 	return account, nil
 }
 
+// LookupAccount resolves an account by its @user@instance address
+// Servers without the lookup endpoint fall back to account search
+func (c *Client) LookupAccount(ctx context.Context, acct string) (*mastodon.Account, error) {
+	acct = strings.TrimPrefix(acct, "@")
+
+	account, err := c.client.AccountLookup(ctx, acct)
+	if err == nil {
+		return account, nil
+	}
+
+	accounts, searchErr := c.client.AccountsSearchResolve(ctx, acct, 1, true)
+	if searchErr != nil || len(accounts) == 0 || !strings.EqualFold(accounts[0].Acct, acct) {
+		return nil, fmt.Errorf("failed to look up account @%s: %w", acct, err)
+	}
+	return accounts[0], nil
+}
+
 // GetStatuses fetches statuses for the authenticated user's account
 // maxID and sinceID are used for pagination
 func (c *Client) GetStatuses(ctx context.Context, accountID mastodon.ID, pg *mastodon.Pagination) ([]*mastodon.Status, error) {
@@ -62,6 +97,187 @@ func (c *Client) GetFavourites(ctx context.Context, pg *mastodon.Pagination) ([]
 	return statuses, nil
 }
 
+// GetHashtagTimeline fetches public statuses tagged with a hashtag
+func (c *Client) GetHashtagTimeline(ctx context.Context, tag string, pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+	statuses, err := c.client.GetTimelineHashtag(ctx, tag, false, pg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hashtag timeline: %w", err)
+	}
+	return statuses, nil
+}
+
+// GetHomeTimeline fetches statuses from accounts the authenticated user follows
+func (c *Client) GetHomeTimeline(ctx context.Context, pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+	statuses, err := c.client.GetTimelineHome(ctx, pg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch home timeline: %w", err)
+	}
+	return statuses, nil
+}
+
+// GetListTimeline fetches statuses from accounts in one of the authenticated user's lists
+func (c *Client) GetListTimeline(ctx context.Context, id mastodon.ID, pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+	statuses, err := c.client.GetTimelineList(ctx, id, pg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch list timeline: %w", err)
+	}
+	return statuses, nil
+}
+
+// GetLists fetches the authenticated user's lists
+func (c *Client) GetLists(ctx context.Context) ([]*mastodon.List, error) {
+	lists, err := c.client.GetLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lists: %w", err)
+	}
+	return lists, nil
+}
+
+// GetPinnedStatuses fetches the statuses pinned to an account's profile
+func (c *Client) GetPinnedStatuses(ctx context.Context, accountID mastodon.ID) ([]*mastodon.Status, error) {
+	statuses, err := c.client.GetAccountPinnedStatuses(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pinned statuses: %w", err)
+	}
+	return statuses, nil
+}
+
+// FeaturedTag is a hashtag featured on an account's profile
+// go-mastodon doesn't support the featured tags endpoint
+type FeaturedTag struct {
+	ID   mastodon.ID `json:"id"`
+	Name string      `json:"name"`
+	URL  string      `json:"url"`
+	// Some Mastodon versions return the count as a string
+	StatusesCount json.Number `json:"statuses_count"`
+	LastStatusAt  *string     `json:"last_status_at"`
+}
+
+// GetFeaturedTags fetches the hashtags featured on an account's profile
+func (c *Client) GetFeaturedTags(ctx context.Context, accountID mastodon.ID) ([]*FeaturedTag, error) {
+	var tags []*FeaturedTag
+	path := "/api/v1/accounts/" + url.PathEscape(string(accountID)) + "/featured_tags"
+	if err := c.getJSON(ctx, path, &tags); err != nil {
+		return nil, fmt.Errorf("failed to fetch featured tags: %w", err)
+	}
+	return tags, nil
+}
+
+// getJSON fetches an API path not covered by go-mastodon and decodes the JSON response
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	u, err := url.JoinPath(c.config.Mastodon.Server, path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if c.config.Mastodon.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Mastodon.AccessToken)
+	}
+	if c.client.UserAgent != "" {
+		req.Header.Set("User-Agent", c.client.UserAgent)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad request: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// accountPageLimit is the number of accounts requested per page, the API maximum
+const accountPageLimit = 80
+
+// FollowAccount is an account in a followers or following list
+type FollowAccount struct {
+	Account *mastodon.Account
+	// Date of the account's latest post, empty if unknown
+	// go-mastodon doesn't decode last_status_at, so it's read from the raw response
+	LastStatusAt string
+}
+
+// GetFollowers pages through all accounts following an account
+func (c *Client) GetFollowers(ctx context.Context, accountID mastodon.ID) ([]*FollowAccount, error) {
+	accounts, err := c.pageAccounts(func(pg *mastodon.Pagination) ([]*mastodon.Account, error) {
+		return c.client.GetAccountFollowers(ctx, accountID, pg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch followers: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetFollowing pages through all accounts an account follows
+func (c *Client) GetFollowing(ctx context.Context, accountID mastodon.ID) ([]*FollowAccount, error) {
+	accounts, err := c.pageAccounts(func(pg *mastodon.Pagination) ([]*mastodon.Account, error) {
+		return c.client.GetAccountFollowing(ctx, accountID, pg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch following: %w", err)
+	}
+	return accounts, nil
+}
+
+// pageAccounts follows Link header pagination through an account list,
+// capturing each raw page to read fields go-mastodon doesn't decode
+func (c *Client) pageAccounts(fetch func(pg *mastodon.Pagination) ([]*mastodon.Account, error)) ([]*FollowAccount, error) {
+	var raw bytes.Buffer
+	c.client.JSONWriter = &raw
+	defer func() { c.client.JSONWriter = nil }()
+
+	all := []*FollowAccount{}
+	pg := &mastodon.Pagination{Limit: accountPageLimit}
+	for {
+		accounts, err := fetch(pg)
+		if err != nil {
+			return nil, err
+		}
+
+		var extra []struct {
+			ID           mastodon.ID `json:"id"`
+			LastStatusAt *string     `json:"last_status_at"`
+		}
+		if err := json.Unmarshal(raw.Bytes(), &extra); err != nil {
+			return nil, fmt.Errorf("failed to parse accounts: %w", err)
+		}
+		lastStatusAt := map[mastodon.ID]string{}
+		for _, e := range extra {
+			if e.LastStatusAt != nil {
+				lastStatusAt[e.ID] = *e.LastStatusAt
+			}
+		}
+
+		for _, account := range accounts {
+			all = append(all, &FollowAccount{Account: account, LastStatusAt: lastStatusAt[account.ID]})
+		}
+
+		// The API omits the next link after the last page
+		if len(accounts) == 0 || pg.MaxID == "" {
+			break
+		}
+		pg = &mastodon.Pagination{MaxID: pg.MaxID, Limit: accountPageLimit}
+	}
+
+	return all, nil
+}
+
+// GetStatusHistory fetches all versions of an edited status, oldest first
+func (c *Client) GetStatusHistory(ctx context.Context, id mastodon.ID) ([]*mastodon.StatusHistory, error) {
+	history, err := c.client.GetStatusHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch status history: %w", err)
+	}
+	return history, nil
+}
+
 // GetClient returns the underlying go-mastodon client for advanced use
 func (c *Client) GetClient() *mastodon.Client {
 	return c.client
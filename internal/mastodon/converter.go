@@ -24,11 +24,21 @@ func ConvertStatus(status *mastodon.Status) templates.Post {
 		IsReply:           status.InReplyToID != nil,
 		IsBoost:           status.Reblog != nil,
 		IsFavorited:       false, // Will be set by ConvertFavourite
+		Author:            convertAuthor(&status.Account),
+		Mentions:          convertMentions(status.Mentions),
+		Links:             extractLinks(status.Content),
+		Emojis:            convertEmojis(status.Emojis, status.Account.Emojis),
+		Tags:              convertTags(status.Tags),
 		RepliesCount:      status.RepliesCount,
 		ReblogsCount:      status.ReblogsCount,
 		FavouritesCount:   status.FavouritesCount,
 	}
 
+	if !status.EditedAt.IsZero() {
+		post.EditedAt = status.EditedAt
+		post.FormattedEditedAt = timerange.FormatDateTime(status.EditedAt)
+	}
+
 	// If this is a boost, extract the original post and any commentary
 	if status.Reblog != nil {
 		post.BoostCommentary = cleanContent(status.Content)
@@ -43,6 +53,8 @@ func ConvertStatus(status *mastodon.Status) templates.Post {
 				Description: attachment.Description,
 			})
 		}
+		post.Card = convertCard(status.Card)
+		post.Poll = convertPoll(status.Poll)
 	}
 
 	return post
@@ -64,15 +76,31 @@ func ConvertFavourite(status *mastodon.Status) templates.Post {
 	return post
 }
 
+// convertAuthor converts the account that wrote a status
+func convertAuthor(account *mastodon.Account) *templates.Author {
+	return &templates.Author{
+		Name:     account.DisplayName,
+		Username: account.Username,
+		Acct:     account.Acct,
+		URL:      account.URL,
+		Avatar:   account.Avatar,
+	}
+}
+
 // extractOriginalPost extracts original post details from a status
 func extractOriginalPost(status *mastodon.Status) *templates.OriginalPost {
 	original := &templates.OriginalPost{
 		AuthorName:     status.Account.DisplayName,
 		AuthorUsername: string(status.Account.Username),
+		AuthorAcct:     status.Account.Acct,
 		AuthorURL:      status.Account.URL,
 		Content:        cleanContent(status.Content),
 		ContentWarning: status.SpoilerText,
 		URL:            status.URL,
+		Mentions:       convertMentions(status.Mentions),
+		Links:          extractLinks(status.Content),
+		Emojis:         convertEmojis(status.Emojis, status.Account.Emojis),
+		Tags:           convertTags(status.Tags),
 	}
 
 	// Convert media attachments
@@ -84,10 +112,150 @@ func extractOriginalPost(status *mastodon.Status) *templates.OriginalPost {
 			Description: attachment.Description,
 		})
 	}
+	original.Card = convertCard(status.Card)
+	original.Poll = convertPoll(status.Poll)
 
 	return original
 }
 
+// convertCard converts a status preview card, returning nil if there is none
+func convertCard(card *mastodon.Card) *templates.Card {
+	if card == nil || card.URL == "" {
+		return nil
+	}
+	return &templates.Card{
+		URL:          card.URL,
+		Title:        strings.TrimSpace(card.Title),
+		Description:  strings.Join(strings.Fields(card.Description), " "),
+		Image:        card.Image,
+		Type:         card.Type,
+		ProviderName: card.ProviderName,
+		AuthorName:   card.AuthorName,
+	}
+}
+
+// convertPoll converts a status poll and computes each option's share of the vote
+// Percentages are relative to voters for multiple-choice polls, matching Mastodon's UI
+func convertPoll(poll *mastodon.Poll) *templates.Poll {
+	if poll == nil {
+		return nil
+	}
+
+	result := &templates.Poll{
+		Multiple:    poll.Multiple,
+		VotesCount:  poll.VotesCount,
+		VotersCount: poll.VotersCount,
+		Closed:      poll.Expired, // As of the fetch, so output doesn't change with the time it's rendered
+		ExpiresAt:   poll.ExpiresAt,
+	}
+	if !poll.ExpiresAt.IsZero() {
+		result.FormattedExpiresAt = timerange.FormatDateTime(poll.ExpiresAt)
+	}
+
+	// Older servers omit voters_count for single-choice polls, where it equals votes_count
+	if result.VotersCount == 0 && !poll.Multiple {
+		result.VotersCount = poll.VotesCount
+	}
+
+	total := poll.VotesCount
+	if poll.Multiple {
+		total = result.VotersCount
+	}
+
+	for _, option := range poll.Options {
+		var percentage float64
+		if total > 0 {
+			percentage = float64(option.VotesCount) / float64(total) * 100
+		}
+		result.Options = append(result.Options, templates.PollOption{
+			Title:      option.Title,
+			VotesCount: option.VotesCount,
+			Percentage: percentage,
+		})
+	}
+
+	return result
+}
+
+// ConvertStatusHistory converts the edit history of a status into revisions
+// The API returns versions oldest first, ending with the current version
+func ConvertStatusHistory(history []*mastodon.StatusHistory) []templates.Revision {
+	revisions := make([]templates.Revision, 0, len(history))
+	for _, version := range history {
+		revision := templates.Revision{
+			CreatedAt:      version.CreatedAt,
+			FormattedTime:  timerange.FormatDateTime(version.CreatedAt),
+			Content:        cleanContent(version.Content),
+			ContentWarning: version.SpoilerText,
+		}
+		for _, attachment := range version.MediaAttachments {
+			revision.MediaAttachments = append(revision.MediaAttachments, templates.MediaAttachment{
+				Type:        string(attachment.Type),
+				URL:         attachment.URL,
+				PreviewURL:  attachment.PreviewURL,
+				Description: attachment.Description,
+			})
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// ConvertProfile converts an account's public profile
+func ConvertProfile(account *mastodon.Account) *templates.Profile {
+	profile := &templates.Profile{
+		Name:               account.DisplayName,
+		Username:           account.Username,
+		Acct:               account.Acct,
+		URL:                account.URL,
+		Avatar:             account.Avatar,
+		Header:             account.Header,
+		Bio:                cleanContent(account.Note),
+		Emojis:             convertEmojis(account.Emojis),
+		FollowersCount:     account.FollowersCount,
+		FollowingCount:     account.FollowingCount,
+		StatusesCount:      account.StatusesCount,
+		CreatedAt:          account.CreatedAt,
+		FormattedCreatedAt: timerange.FormatDate(account.CreatedAt),
+		Bot:                account.Bot,
+		Locked:             account.Locked,
+	}
+
+	for _, field := range account.Fields {
+		converted := templates.ProfileField{
+			Name:  field.Name,
+			Value: cleanContent(field.Value),
+		}
+		if !field.VerifiedAt.IsZero() {
+			verifiedAt := field.VerifiedAt
+			converted.Verified = true
+			converted.VerifiedAt = &verifiedAt
+		}
+		profile.Fields = append(profile.Fields, converted)
+	}
+
+	return profile
+}
+
+// ConvertFeaturedTags converts the hashtags featured on a profile
+func ConvertFeaturedTags(tags []*FeaturedTag) []templates.FeaturedTag {
+	featured := make([]templates.FeaturedTag, 0, len(tags))
+	for _, tag := range tags {
+		count, _ := tag.StatusesCount.Int64()
+		lastStatusAt := ""
+		if tag.LastStatusAt != nil {
+			lastStatusAt = *tag.LastStatusAt
+		}
+		featured = append(featured, templates.FeaturedTag{
+			Name:          tag.Name,
+			URL:           tag.URL,
+			StatusesCount: count,
+			LastStatusAt:  lastStatusAt,
+		})
+	}
+	return featured
+}
+
 // ConvertStatuses converts multiple Mastodon statuses
 func ConvertStatuses(statuses []*mastodon.Status) []templates.Post {
 	posts := make([]templates.Post, 0, len(statuses))
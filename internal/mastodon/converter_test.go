@@ -0,0 +1,144 @@
+package mastodon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/mattn/go-mastodon"
+)
+
+func TestConvertCard(t *testing.T) {
+	if got := convertCard(nil); got != nil {
+		t.Errorf("convertCard(nil) = %+v, want nil", got)
+	}
+	if got := convertCard(&mastodon.Card{Title: "No URL"}); got != nil {
+		t.Errorf("card without a URL = %+v, want nil", got)
+	}
+
+	got := convertCard(&mastodon.Card{
+		URL:          "https://news.example/story",
+		Title:        "  A story \n",
+		Description:  "First line\n\n  second   line ",
+		Image:        "https://news.example/og.png",
+		Type:         "link",
+		ProviderName: "The Example Times",
+		AuthorName:   "Reporter",
+	})
+	want := &templates.Card{
+		URL:          "https://news.example/story",
+		Title:        "A story",
+		Description:  "First line second line",
+		Image:        "https://news.example/og.png",
+		Type:         "link",
+		ProviderName: "The Example Times",
+		AuthorName:   "Reporter",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("convertCard() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConvertPoll(t *testing.T) {
+	past := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	future := time.Date(2999, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		poll        mastodon.Poll
+		closed      bool
+		voters      int64
+		percentages []float64
+	}{
+		{
+			name:        "expired single choice, voters from votes",
+			poll:        mastodon.Poll{ExpiresAt: past, Expired: true, VotesCount: 4, Options: []mastodon.PollOption{{Title: "a", VotesCount: 3}, {Title: "b", VotesCount: 1}}},
+			closed:      true,
+			voters:      4,
+			percentages: []float64{75, 25},
+		},
+		{
+			// Closed comes from the server, not the clock, so output is stable
+			name:        "past expiry the server hasn't closed",
+			poll:        mastodon.Poll{ExpiresAt: past, VotesCount: 2, Options: []mastodon.PollOption{{Title: "a", VotesCount: 2}}},
+			voters:      2,
+			percentages: []float64{100},
+		},
+		{
+			name:        "multiple choice, shares of voters",
+			poll:        mastodon.Poll{ExpiresAt: future, Multiple: true, VotesCount: 6, VotersCount: 4, Options: []mastodon.PollOption{{Title: "a", VotesCount: 4}, {Title: "b", VotesCount: 2}}},
+			voters:      4,
+			percentages: []float64{100, 50},
+		},
+		{
+			name:        "no votes",
+			poll:        mastodon.Poll{Options: []mastodon.PollOption{{Title: "a"}, {Title: "b"}}},
+			percentages: []float64{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := convertPoll(&tt.poll)
+			if got.Closed != tt.closed || got.VotersCount != tt.voters {
+				t.Errorf("closed = %v, voters = %d, want %v, %d", got.Closed, got.VotersCount, tt.closed, tt.voters)
+			}
+			var percentages []float64
+			for _, option := range got.Options {
+				percentages = append(percentages, option.Percentage)
+			}
+			if !reflect.DeepEqual(percentages, tt.percentages) {
+				t.Errorf("percentages = %v, want %v", percentages, tt.percentages)
+			}
+			if tt.poll.ExpiresAt.IsZero() != (got.FormattedExpiresAt == "") {
+				t.Errorf("FormattedExpiresAt = %q for expiry %v", got.FormattedExpiresAt, tt.poll.ExpiresAt)
+			}
+		})
+	}
+
+	if convertPoll(nil) != nil {
+		t.Error("convertPoll(nil) should be nil")
+	}
+}
+
+func TestConvertStatusHistory(t *testing.T) {
+	history := []*mastodon.StatusHistory{
+		{
+			Content:   "<p>First draft &amp; typo</p>",
+			CreatedAt: time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			Content:     "<p>Fixed</p><p>second line</p>",
+			SpoilerText: "spoilers",
+			CreatedAt:   time.Date(2024, 1, 15, 10, 5, 0, 0, time.UTC),
+			MediaAttachments: []mastodon.Attachment{
+				{Type: "image", URL: "https://example.com/a.png", PreviewURL: "https://example.com/a-small.png", Description: "A cat"},
+			},
+		},
+	}
+
+	got := ConvertStatusHistory(history)
+	want := []templates.Revision{
+		{
+			CreatedAt:     history[0].CreatedAt,
+			FormattedTime: "2024-01-15 09:30",
+			Content:       "First draft & typo",
+		},
+		{
+			CreatedAt:      history[1].CreatedAt,
+			FormattedTime:  "2024-01-15 10:05",
+			Content:        "Fixed\n\nsecond line",
+			ContentWarning: "spoilers",
+			MediaAttachments: []templates.MediaAttachment{
+				{Type: "image", URL: "https://example.com/a.png", PreviewURL: "https://example.com/a-small.png", Description: "A cat"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConvertStatusHistory() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := ConvertStatusHistory(nil); len(got) != 0 {
+		t.Errorf("ConvertStatusHistory(nil) = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,192 @@
+package mastodon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/mattn/go-mastodon"
+)
+
+// Emoji rendering modes
+const (
+	// EmojiShortcode leaves custom emoji as ":shortcode:" text
+	EmojiShortcode = "shortcode"
+
+	// EmojiImage renders custom emoji as markdown images pointing at the emoji URL
+	EmojiImage = "image"
+
+	// EmojiDrop removes custom emoji shortcodes entirely
+	EmojiDrop = "drop"
+)
+
+// convertEmojis converts custom emoji lists, skipping duplicate shortcodes
+func convertEmojis(lists ...[]mastodon.Emoji) []templates.Emoji {
+	var result []templates.Emoji
+	seen := map[string]bool{}
+	for _, emojis := range lists {
+		for _, emoji := range emojis {
+			if seen[emoji.ShortCode] {
+				continue
+			}
+			seen[emoji.ShortCode] = true
+			result = append(result, templates.Emoji{
+				Shortcode: emoji.ShortCode,
+				URL:       emoji.URL,
+				StaticURL: emoji.StaticURL,
+			})
+		}
+	}
+	return result
+}
+
+// ApplyEmojiMode rewrites custom emoji shortcodes in post text according to mode
+// Content, content warnings, boost commentary, revisions, card titles, poll
+// options, and author names are affected
+// Only shortcodes listed in a post's Emojis are replaced
+func ApplyEmojiMode(posts []templates.Post, mode string) error {
+	if replace, err := replacesEmoji(mode); !replace {
+		return err
+	}
+
+	rewritePosts(posts, func(emojis []templates.Emoji) *strings.Replacer {
+		return emojiReplacer(emojis, mode)
+	})
+	return nil
+}
+
+// RelinkEmojiImages points the emoji images rendered by the "image" mode at
+// downloaded files, keyed by emoji URL, in posts and a profile, which may be nil
+func RelinkEmojiImages(profile *templates.Profile, posts []templates.Post, files map[string]string) {
+	relinker := func(emojis []templates.Emoji) *strings.Replacer {
+		var pairs []string
+		for _, emoji := range emojis {
+			if file, ok := files[emoji.URL]; ok {
+				local := emoji
+				local.URL = file
+				pairs = append(pairs, templates.EmojiImage(emoji), templates.EmojiImage(local))
+			}
+		}
+		return strings.NewReplacer(pairs...)
+	}
+
+	rewritePosts(posts, relinker)
+	if profile != nil {
+		rewriteProfile(profile, relinker(profile.Emojis))
+	}
+}
+
+// EmojiImageURLs returns the distinct URLs of the custom emoji in posts and a
+// profile, which may be nil, for downloading
+func EmojiImageURLs(profile *templates.Profile, posts []templates.Post) []string {
+	lists := [][]templates.Emoji{}
+	if profile != nil {
+		lists = append(lists, profile.Emojis)
+	}
+	for _, post := range posts {
+		lists = append(lists, post.Emojis)
+		if post.OriginalPost != nil {
+			lists = append(lists, post.OriginalPost.Emojis)
+		}
+	}
+
+	seen := map[string]bool{}
+	urls := []string{}
+	for _, emojis := range lists {
+		for _, emoji := range emojis {
+			if emoji.URL != "" && !seen[emoji.URL] {
+				seen[emoji.URL] = true
+				urls = append(urls, emoji.URL)
+			}
+		}
+	}
+	return urls
+}
+
+// rewritePosts runs the text of posts that can hold custom emoji through a
+// replacer built from the emoji each post uses
+func rewritePosts(posts []templates.Post, replacerFor func([]templates.Emoji) *strings.Replacer) {
+	for i := range posts {
+		post := &posts[i]
+		replacer := replacerFor(post.Emojis)
+		post.Content = replacer.Replace(post.Content)
+		post.ContentWarning = replacer.Replace(post.ContentWarning)
+		post.BoostCommentary = replacer.Replace(post.BoostCommentary)
+		if post.Author != nil {
+			post.Author.Name = strings.TrimSpace(replacer.Replace(post.Author.Name))
+		}
+		for j := range post.Revisions {
+			post.Revisions[j].Content = replacer.Replace(post.Revisions[j].Content)
+			post.Revisions[j].ContentWarning = replacer.Replace(post.Revisions[j].ContentWarning)
+		}
+		rewriteCardAndPoll(post.Card, post.Poll, replacer)
+
+		if original := post.OriginalPost; original != nil {
+			replacer := replacerFor(original.Emojis)
+			original.Content = replacer.Replace(original.Content)
+			original.ContentWarning = replacer.Replace(original.ContentWarning)
+			original.AuthorName = strings.TrimSpace(replacer.Replace(original.AuthorName))
+			rewriteCardAndPoll(original.Card, original.Poll, replacer)
+		}
+	}
+}
+
+// rewriteCardAndPoll rewrites a card's title and a poll's options, either of which may be nil
+func rewriteCardAndPoll(card *templates.Card, poll *templates.Poll, replacer *strings.Replacer) {
+	if card != nil {
+		card.Title = strings.TrimSpace(replacer.Replace(card.Title))
+	}
+	if poll != nil {
+		for i := range poll.Options {
+			poll.Options[i].Title = strings.TrimSpace(replacer.Replace(poll.Options[i].Title))
+		}
+	}
+}
+
+// emojiReplacer builds a replacer that swaps ":shortcode:" for the mode's rendering
+func emojiReplacer(emojis []templates.Emoji, mode string) *strings.Replacer {
+	var pairs []string
+	for _, emoji := range emojis {
+		shortcode := ":" + emoji.Shortcode + ":"
+		switch mode {
+		case EmojiImage:
+			pairs = append(pairs, shortcode, templates.EmojiImage(emoji))
+		case EmojiDrop:
+			pairs = append(pairs, shortcode, "")
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// ApplyProfileEmojiMode renders custom emoji shortcodes in a profile's name,
+// bio, and fields according to mode
+func ApplyProfileEmojiMode(profile *templates.Profile, mode string) error {
+	if replace, err := replacesEmoji(mode); !replace {
+		return err
+	}
+
+	rewriteProfile(profile, emojiReplacer(profile.Emojis, mode))
+	return nil
+}
+
+// rewriteProfile runs a profile's name, bio, and fields through a replacer
+func rewriteProfile(profile *templates.Profile, replacer *strings.Replacer) {
+	profile.Name = strings.TrimSpace(replacer.Replace(profile.Name))
+	profile.Bio = replacer.Replace(profile.Bio)
+	for i := range profile.Fields {
+		profile.Fields[i].Name = replacer.Replace(profile.Fields[i].Name)
+		profile.Fields[i].Value = replacer.Replace(profile.Fields[i].Value)
+	}
+}
+
+// replacesEmoji reports whether mode replaces shortcodes, or an error for an unknown mode
+func replacesEmoji(mode string) (bool, error) {
+	switch mode {
+	case "", EmojiShortcode:
+		return false, nil
+	case EmojiImage, EmojiDrop:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown emoji mode %q (expected %s, %s, or %s)", mode, EmojiShortcode, EmojiImage, EmojiDrop)
+	}
+}
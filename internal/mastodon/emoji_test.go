@@ -0,0 +1,55 @@
+package mastodon
+
+import (
+	"testing"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+func TestApplyEmojiModeImage(t *testing.T) {
+	emojis := []templates.Emoji{{Shortcode: "blobcat", URL: "https://example.social/blobcat.png"}}
+	posts := []templates.Post{{
+		Content:   "Hi :blobcat:",
+		Emojis:    emojis,
+		Revisions: []templates.Revision{{Content: "Hello :blobcat:", ContentWarning: ":blobcat:"}},
+		Card:      &templates.Card{Title: "Cats :blobcat:"},
+		Poll:      &templates.Poll{Options: []templates.PollOption{{Title: ":blobcat: yes"}, {Title: ":nope:"}}},
+	}}
+
+	if err := ApplyEmojiMode(posts, EmojiImage); err != nil {
+		t.Fatalf("ApplyEmojiMode failed: %v", err)
+	}
+
+	img := "![:blobcat:](https://example.social/blobcat.png)"
+	post := posts[0]
+	for _, tt := range []struct{ name, got, want string }{
+		{"content", post.Content, "Hi " + img},
+		{"revision", post.Revisions[0].Content, "Hello " + img},
+		{"revision warning", post.Revisions[0].ContentWarning, img},
+		{"card title", post.Card.Title, "Cats " + img},
+		{"poll option", post.Poll.Options[0].Title, img + " yes"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if got := post.Poll.Options[1].Title; got != ":nope:" {
+		t.Errorf("unknown shortcode was replaced: %q", got)
+	}
+
+	profile := &templates.Profile{Name: "Me :blobcat:", Emojis: emojis}
+	if err := ApplyProfileEmojiMode(profile, EmojiImage); err != nil {
+		t.Fatalf("ApplyProfileEmojiMode failed: %v", err)
+	}
+	if got := EmojiImageURLs(profile, posts); len(got) != 1 || got[0] != emojis[0].URL {
+		t.Errorf("EmojiImageURLs = %v, want the emoji once", got)
+	}
+
+	RelinkEmojiImages(profile, posts, map[string]string{emojis[0].URL: "emoji-1.png"})
+	if want := "Me ![:blobcat:](emoji-1.png)"; profile.Name != want {
+		t.Errorf("profile name = %q, want %q", profile.Name, want)
+	}
+	if want := "Cats ![:blobcat:](emoji-1.png)"; posts[0].Card.Title != want {
+		t.Errorf("card title = %q, want %q", posts[0].Card.Title, want)
+	}
+}
@@ -0,0 +1,110 @@
+package mastodon
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/mattn/go-mastodon"
+	"golang.org/x/net/html"
+)
+
+// convertMentions converts the accounts mentioned in a status
+func convertMentions(mentions []mastodon.Mention) []templates.Mention {
+	var result []templates.Mention
+	for _, mention := range mentions {
+		result = append(result, templates.Mention{
+			Username: mention.Username,
+			Acct:     mention.Acct,
+			URL:      mention.URL,
+		})
+	}
+	return result
+}
+
+// convertTags returns the lowercased names of the hashtags used in a status
+func convertTags(tags []mastodon.Tag) []string {
+	var result []string
+	for _, tag := range tags {
+		result = append(result, strings.ToLower(tag.Name))
+	}
+	return result
+}
+
+// extractLinks parses status HTML and returns the outbound links it contains
+// Mastodon marks mention and hashtag links with a "mention" class, so those are skipped
+func extractLinks(content string) []templates.Link {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+
+	var links []templates.Link
+	seen := map[string]bool{}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			href := attr(n, "href")
+			if href != "" && !hasClass(n, "mention") && !seen[href] {
+				if domain := linkDomain(href); domain != "" {
+					seen[href] = true
+					links = append(links, templates.Link{
+						Href:   href,
+						Text:   strings.TrimSpace(textContent(n)),
+						Domain: domain,
+					})
+				}
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return links
+}
+
+// linkDomain returns the host of an http(s) URL without a leading "www."
+// Returns an empty string for anything that isn't a web link
+func linkDomain(href string) string {
+	u, err := url.Parse(href)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// attr returns the value of the named attribute, or an empty string
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasClass reports whether the node's class attribute contains the given class
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the concatenated text of a node and its descendants
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
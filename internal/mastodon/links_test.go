@@ -0,0 +1,45 @@
+package mastodon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []templates.Link
+	}{
+		{
+			name:    "mentions and hashtags skipped",
+			content: `<p><span class="h-card"><a href="https://other.example/@bob" class="u-url mention">@<span>bob</span></a></span> see <a href="https://fake.example/tags/go" class="mention hashtag" rel="tag">#<span>go</span></a></p>`,
+		},
+		{
+			name:    "visible text and www stripped from the domain",
+			content: `<p>Read <a href="https://www.Blog.example/post?a=1&amp;b=2"><span class="invisible">https://www.</span><span class="ellipsis">blog.example/post</span></a></p>`,
+			want:    []templates.Link{{Href: "https://www.Blog.example/post?a=1&b=2", Text: "https://www.blog.example/post", Domain: "blog.example"}},
+		},
+		{
+			name:    "repeated hrefs listed once",
+			content: `<p><a href="https://news.example/a">one</a> <a href="https://news.example/a">two</a> <a href="https://news.example/b">three</a></p>`,
+			want: []templates.Link{
+				{Href: "https://news.example/a", Text: "one", Domain: "news.example"},
+				{Href: "https://news.example/b", Text: "three", Domain: "news.example"},
+			},
+		},
+		{
+			name:    "non-web links skipped",
+			content: `<p><a href="mailto:me@example.com">mail</a> <a href="/relative">here</a> <a>nothing</a></p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractLinks(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractLinks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
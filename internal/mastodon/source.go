@@ -0,0 +1,280 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	"github.com/mattn/go-mastodon"
+)
+
+// pageLimit is the number of statuses requested per page
+const pageLimit = 40
+
+// AccountSource reads an account's statuses, and the authenticated account's
+// favourites, from the live API
+type AccountSource struct {
+	client  *Client
+	account *mastodon.Account
+	own     bool // Whether account is the authenticated account
+}
+
+// NewAccountSource verifies credentials and returns a source for the authenticated account
+func NewAccountSource(ctx context.Context, client *Client) (*AccountSource, error) {
+	account, err := client.VerifyCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountSource{client: client, account: account, own: true}, nil
+}
+
+// NewLookupSource resolves an @user@instance address and returns a source for
+// that account's statuses, as visible to the client
+func NewLookupSource(ctx context.Context, client *Client, acct string) (*AccountSource, error) {
+	account, err := client.LookupAccount(ctx, acct)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountSource{client: client, account: account}, nil
+}
+
+// Account returns the account being exported
+func (s *AccountSource) Account(ctx context.Context) (*mastodon.Account, error) {
+	return s.account, nil
+}
+
+// StatusesInRange pages back through the account's statuses, newest first,
+// until it passes the start of the time range
+func (s *AccountSource) StatusesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodon.Status, error) {
+	return statusesInRange(ctx, tr, func(pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+		return s.client.GetStatuses(ctx, s.account.ID, pg)
+	})
+}
+
+// statusesInRange pages back through a newest-first timeline until it passes
+// the start of the time range, collecting statuses created in the range
+func statusesInRange(ctx context.Context, tr *timerange.TimeRange, fetch func(pg *mastodon.Pagination) ([]*mastodon.Status, error)) ([]*mastodon.Status, error) {
+	allStatuses := []*mastodon.Status{}
+	var maxID mastodon.ID
+
+	// Pagination loop
+	for {
+		pg := &mastodon.Pagination{
+			MaxID: maxID,
+			Limit: pageLimit,
+		}
+
+		statuses, err := fetch(pg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(statuses) == 0 {
+			break
+		}
+
+		// Filter by time range
+		foundInRange := false
+		for _, status := range statuses {
+			if status.CreatedAt.Before(tr.Start) {
+				// We've gone past our time range
+				break
+			}
+			if status.CreatedAt.After(tr.End) {
+				// Haven't reached our time range yet
+				continue
+			}
+			foundInRange = true
+			allStatuses = append(allStatuses, status)
+		}
+
+		// If the last status is before our start time, we're done
+		if statuses[len(statuses)-1].CreatedAt.Before(tr.Start) {
+			break
+		}
+
+		// If we didn't find any in range and we're past the end, keep going
+		if !foundInRange && statuses[len(statuses)-1].CreatedAt.Before(tr.End) {
+			break
+		}
+
+		maxID = statuses[len(statuses)-1].ID
+	}
+
+	return allStatuses, nil
+}
+
+// FavouritesInRange pages through the authenticated user's favourites for posts
+// created in the time range
+// Favourites are ordered by when they were favourited, not when the post was
+// created, so paging stops after consecutive pages without matches or a page limit
+// Favourites are private, so looked-up accounts have none
+func (s *AccountSource) FavouritesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodon.Status, error) {
+	allFavorites := []*mastodon.Status{}
+	if !s.own {
+		return allFavorites, nil
+	}
+	var maxID mastodon.ID
+
+	// Pagination loop for favorites with smart stopping
+	consecutiveEmptyPages := 0
+	maxConsecutiveEmpty := 2 // Stop after 2 pages with no matches
+	maxTotalPages := 3       // Safety limit: ~120 favorites
+
+	for pageCount := 0; pageCount < maxTotalPages; pageCount++ {
+		pg := &mastodon.Pagination{
+			MaxID: maxID,
+			Limit: pageLimit,
+		}
+
+		favorites, err := s.client.GetFavourites(ctx, pg)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(favorites) == 0 {
+			break
+		}
+
+		// Filter by time range
+		foundInRange := false
+		for _, status := range favorites {
+			if status.CreatedAt.Before(tr.Start) {
+				continue
+			}
+			if status.CreatedAt.After(tr.End) {
+				continue
+			}
+			foundInRange = true
+			allFavorites = append(allFavorites, status)
+		}
+
+		// Smart stopping: if no matches in recent pages, we're probably past the date range
+		if !foundInRange {
+			consecutiveEmptyPages++
+			if consecutiveEmptyPages >= maxConsecutiveEmpty {
+				break
+			}
+		} else {
+			consecutiveEmptyPages = 0 // Reset counter on match
+		}
+
+		maxID = favorites[len(favorites)-1].ID
+	}
+
+	return allFavorites, nil
+}
+
+// PinnedStatuses fetches the statuses pinned to the account's profile
+func (s *AccountSource) PinnedStatuses(ctx context.Context) ([]*mastodon.Status, error) {
+	return s.client.GetPinnedStatuses(ctx, s.account.ID)
+}
+
+// FeaturedTags fetches the hashtags featured on the account's profile
+func (s *AccountSource) FeaturedTags(ctx context.Context) ([]templates.FeaturedTag, error) {
+	tags, err := s.client.GetFeaturedTags(ctx, s.account.ID)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertFeaturedTags(tags), nil
+}
+
+// StatusHistory fetches all versions of an edited status, oldest first
+func (s *AccountSource) StatusHistory(ctx context.Context, id mastodon.ID) ([]*mastodon.StatusHistory, error) {
+	return s.client.GetStatusHistory(ctx, id)
+}
+
+// HashtagSource reads public statuses tagged with a hashtag from the live API
+// The hashtag timeline doesn't require authentication
+type HashtagSource struct {
+	client *Client
+	tag    string
+}
+
+// NewHashtagSource returns a source for a hashtag, with or without a leading #
+func NewHashtagSource(client *Client, tag string) *HashtagSource {
+	return &HashtagSource{client: client, tag: strings.TrimPrefix(tag, "#")}
+}
+
+// Account returns nil, since hashtag timelines span many accounts
+func (s *HashtagSource) Account(ctx context.Context) (*mastodon.Account, error) {
+	return nil, nil
+}
+
+// StatusesInRange pages back through the hashtag timeline until it passes the
+// start of the time range
+func (s *HashtagSource) StatusesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodon.Status, error) {
+	return statusesInRange(ctx, tr, func(pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+		return s.client.GetHashtagTimeline(ctx, s.tag, pg)
+	})
+}
+
+// FavouritesInRange returns no statuses, since favourites belong to an account
+func (s *HashtagSource) FavouritesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodon.Status, error) {
+	return []*mastodon.Status{}, nil
+}
+
+// StatusHistory fetches all versions of an edited status, oldest first
+func (s *HashtagSource) StatusHistory(ctx context.Context, id mastodon.ID) ([]*mastodon.StatusHistory, error) {
+	return s.client.GetStatusHistory(ctx, id)
+}
+
+// TimelineSource reads the authenticated user's home timeline or one of their
+// list timelines from the live API
+type TimelineSource struct {
+	client *Client
+	fetch  func(ctx context.Context, pg *mastodon.Pagination) ([]*mastodon.Status, error)
+}
+
+// NewHomeSource returns a source for the authenticated user's home timeline
+func NewHomeSource(client *Client) *TimelineSource {
+	return &TimelineSource{client: client, fetch: client.GetHomeTimeline}
+}
+
+// NewListSource returns a source for one of the authenticated user's lists,
+// matched by ID or by title, ignoring case
+func NewListSource(ctx context.Context, client *Client, nameOrID string) (*TimelineSource, error) {
+	lists, err := client.GetLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, list := range lists {
+		if string(list.ID) == nameOrID || strings.EqualFold(list.Title, nameOrID) {
+			id := list.ID
+			return &TimelineSource{
+				client: client,
+				fetch: func(ctx context.Context, pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+					return client.GetListTimeline(ctx, id, pg)
+				},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no list named %q", nameOrID)
+}
+
+// Account returns nil, since timelines span many accounts
+func (s *TimelineSource) Account(ctx context.Context) (*mastodon.Account, error) {
+	return nil, nil
+}
+
+// StatusesInRange pages back through the timeline until it passes the start
+// of the time range
+func (s *TimelineSource) StatusesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodon.Status, error) {
+	return statusesInRange(ctx, tr, func(pg *mastodon.Pagination) ([]*mastodon.Status, error) {
+		return s.fetch(ctx, pg)
+	})
+}
+
+// FavouritesInRange returns no statuses, since a timeline digest is about
+// other accounts' posts
+func (s *TimelineSource) FavouritesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodon.Status, error) {
+	return []*mastodon.Status{}, nil
+}
+
+// StatusHistory fetches all versions of an edited status, oldest first
+func (s *TimelineSource) StatusHistory(ctx context.Context, id mastodon.ID) ([]*mastodon.StatusHistory, error) {
+	return s.client.GetStatusHistory(ctx, id)
+}
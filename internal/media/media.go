@@ -0,0 +1,83 @@
+// Package media downloads remote media files, such as avatars and attachments,
+// for use alongside exported documents
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Downloader saves remote files into a local directory
+type Downloader struct {
+	Client *http.Client
+	Dir    string
+}
+
+// NewDownloader returns a downloader that saves files into dir
+func NewDownloader(dir string) *Downloader {
+	return &Downloader{Client: http.DefaultClient, Dir: dir}
+}
+
+// Download saves the file at rawURL in the directory as name, plus an extension
+// taken from the URL or the response's content type, and returns its path
+func (d *Downloader) Download(ctx context.Context, rawURL, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", rawURL, resp.Status)
+	}
+
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create media directory %s: %w", d.Dir, err)
+	}
+
+	filename := filepath.Join(d.Dir, name+extension(rawURL, resp.Header.Get("Content-Type")))
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return filename, nil
+}
+
+// extension picks a file extension from a URL's path, falling back to its content type
+func extension(rawURL, contentType string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := path.Ext(u.Path); ext != "" && len(ext) <= 5 {
+			return strings.ToLower(ext)
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+			// Prefer the common spelling over alphabetically earlier ones like .jfif
+			for _, ext := range exts {
+				if ext == ".jpg" {
+					return ext
+				}
+			}
+			return exts[0]
+		}
+	}
+	return ""
+}
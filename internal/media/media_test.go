@@ -0,0 +1,55 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/avatars/alice.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		case "/headers/original":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("jpeg"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "media")
+	downloader := NewDownloader(dir)
+	ctx := context.Background()
+
+	tests := []struct {
+		url  string
+		name string
+		want string
+	}{
+		{url: server.URL + "/avatars/alice.png", name: "avatar", want: "avatar.png"},
+		{url: server.URL + "/headers/original", name: "header", want: "header.jpg"},
+	}
+	for _, tt := range tests {
+		got, err := downloader.Download(ctx, tt.url, tt.name)
+		if err != nil {
+			t.Fatalf("Download(%s) failed: %v", tt.url, err)
+		}
+		if got != filepath.Join(dir, tt.want) {
+			t.Errorf("Download(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+		if _, err := os.Stat(got); err != nil {
+			t.Errorf("downloaded file missing: %v", err)
+		}
+	}
+
+	if _, err := downloader.Download(ctx, server.URL+"/missing.png", "missing"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
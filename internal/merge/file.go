@@ -0,0 +1,44 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File merges rendered output into the document at path, creating it if it
+// doesn't exist, and reports whether the file changed
+func File(path string, rendered []byte, prune bool) (Result, bool, error) {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return Result{}, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	merged, result, err := Merge(old, rendered, prune)
+	if err != nil {
+		return result, false, fmt.Errorf("failed to merge into %s: %w", path, err)
+	}
+	if bytes.Equal(merged, old) {
+		return result, false, nil
+	}
+
+	// Write to a temporary file first, so a failed write never loses the edits
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return result, false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(merged); err != nil {
+		tmp.Close()
+		return result, false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return result, false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return result, false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return result, true, nil
+}
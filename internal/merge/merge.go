@@ -0,0 +1,282 @@
+// Package merge refreshes a previously rendered document with new output,
+// keeping hand edits, using status ID markers around each post
+package merge
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Markers around each post, on lines of their own; templates write the start
+// marker without a sum, and Merge adds a checksum of the post as it was written
+var (
+	startPattern = regexp.MustCompile(`^<!-- status:(\S+)(?: sum:([0-9a-f]+))? -->$`)
+	endPattern   = regexp.MustCompile(`^<!-- /status:(\S+) -->$`)
+)
+
+// Result counts what a merge did with each post
+type Result struct {
+	Added     int // New posts inserted
+	Updated   int // Unedited posts replaced with their new rendering
+	Unchanged int // Unedited posts that render the same
+	Edited    int // Hand-edited posts kept as they are
+	Removed   int // Posts no longer in the output, removed with prune
+}
+
+// segment is either text outside posts or a post
+type segment struct {
+	text  string
+	block *block
+}
+
+// block is one post between markers
+type block struct {
+	id   string
+	sum  string // Checksum of body when written, empty if never merged
+	body string // Lines between the markers, each ending in a newline
+}
+
+// edited reports whether the post was changed by hand since it was written
+func (b *block) edited() bool {
+	return b.sum == "" || b.sum != checksum(b.body)
+}
+
+// String returns the post with its markers, stamping the sum
+func (b *block) String() string {
+	return fmt.Sprintf("<!-- status:%s sum:%s -->\n%s<!-- /status:%s -->\n", b.id, b.sum, b.body, b.id)
+}
+
+// checksum returns a short hash of a post's text
+func checksum(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Merge updates old, a document previously written by Merge, with rendered,
+// new output with markers around each post
+//
+// Text outside posts, such as commentary, stays where it is. Posts edited by
+// hand are kept as they are; other posts are replaced with their new rendering.
+// New posts are inserted next to their neighbours in the new output. Posts that
+// are no longer in the output are kept, unless prune is set and they weren't edited
+func Merge(old, rendered []byte, prune bool) ([]byte, Result, error) {
+	var result Result
+
+	fresh, err := parse(rendered)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to parse new output: %w", err)
+	}
+	for _, seg := range fresh {
+		if seg.block != nil {
+			seg.block.sum = checksum(seg.block.body)
+		}
+	}
+
+	if len(bytes.TrimSpace(old)) == 0 {
+		for _, seg := range fresh {
+			if seg.block != nil {
+				result.Added++
+			}
+		}
+		return join(fresh), result, nil
+	}
+
+	doc, err := parse(old)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to parse existing document: %w", err)
+	}
+	if findBlock(doc, "") < 0 {
+		return nil, result, fmt.Errorf("existing document has no status markers, so it wasn't written by a merge")
+	}
+
+	// Update posts that are in both, and prune the ones that are gone
+	freshBlocks := map[string]*block{}
+	for _, seg := range fresh {
+		if seg.block != nil {
+			freshBlocks[seg.block.id] = seg.block
+		}
+	}
+	merged := make([]segment, 0, len(doc))
+	for _, seg := range doc {
+		if seg.block == nil {
+			merged = append(merged, seg)
+			continue
+		}
+		next, ok := freshBlocks[seg.block.id]
+		switch {
+		case !ok && prune && !seg.block.edited():
+			result.Removed++
+			continue
+		case !ok:
+			// Kept, though no longer in the output
+		case seg.block.edited():
+			result.Edited++
+		case seg.block.body == next.body:
+			result.Unchanged++
+		default:
+			seg = segment{block: next}
+			result.Updated++
+		}
+		merged = append(merged, seg)
+	}
+
+	// Insert new posts in the order of the new output, so each one's
+	// neighbours are already in place
+	for i, seg := range fresh {
+		if seg.block == nil || findBlock(merged, seg.block.id) >= 0 {
+			continue
+		}
+		merged = insert(merged, fresh, i)
+		result.Added++
+	}
+
+	return join(merged), result, nil
+}
+
+// insert places the new post at fresh[i] into merged
+//
+// A post in the same section as the post after it (nothing but blank lines
+// between them in the new output) goes right before that post, so it joins the
+// existing section. Otherwise it goes right after the post before it, along with
+// the text between them in the new output, such as a heading for a new day.
+func insert(merged, fresh []segment, i int) []segment {
+	post := fresh[i]
+	prevText, prev := neighbour(fresh, i, -1)
+	nextText, next := neighbour(fresh, i, 1)
+
+	if next != "" && strings.TrimSpace(nextText) == "" {
+		if at := findBlock(merged, next); at >= 0 {
+			return splice(merged, at, post, segment{text: nextText})
+		}
+	}
+	if prev != "" {
+		if at := findBlock(merged, prev); at >= 0 {
+			return splice(merged, at+1, segment{text: prevText}, post)
+		}
+	}
+
+	// Before the first post, without headings that likely already exist
+	if at := findBlock(merged, ""); at >= 0 {
+		return splice(merged, at, post, segment{text: "\n"})
+	}
+
+	// With no posts left, such as after pruning all of them, after the text at the top
+	at := 0
+	if len(merged) > 0 {
+		at = 1
+	}
+	return splice(merged, at, post, segment{text: "\n"})
+}
+
+// neighbour returns the text between fresh[i] and the closest post before
+// (dir -1) or after (dir 1) it, and that post's ID, empty if there is none
+func neighbour(fresh []segment, i, dir int) (string, string) {
+	var text []string
+	for j := i + dir; j >= 0 && j < len(fresh); j += dir {
+		if fresh[j].block != nil {
+			if dir < 0 {
+				for l, r := 0, len(text)-1; l < r; l, r = l+1, r-1 {
+					text[l], text[r] = text[r], text[l]
+				}
+			}
+			return strings.Join(text, ""), fresh[j].block.id
+		}
+		text = append(text, fresh[j].text)
+	}
+	return "", ""
+}
+
+// findBlock returns the index of the post with an ID, or of the first post if
+// id is empty, or -1 if there is none
+func findBlock(segments []segment, id string) int {
+	for i, seg := range segments {
+		if seg.block != nil && (id == "" || seg.block.id == id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splice inserts segments at an index
+func splice(segments []segment, at int, insert ...segment) []segment {
+	out := make([]segment, 0, len(segments)+len(insert))
+	out = append(out, segments[:at]...)
+	out = append(out, insert...)
+	return append(out, segments[at:]...)
+}
+
+// join writes segments back into a document
+func join(segments []segment) []byte {
+	var buf bytes.Buffer
+	for _, seg := range segments {
+		if seg.block != nil {
+			buf.WriteString(seg.block.String())
+		} else {
+			buf.WriteString(seg.text)
+		}
+	}
+	return buf.Bytes()
+}
+
+// parse splits a document into text and posts
+func parse(doc []byte) ([]segment, error) {
+	var segments []segment
+	var text strings.Builder
+	var current *block
+	var body strings.Builder
+	seen := map[string]bool{}
+
+	reader := bufio.NewReader(bytes.NewReader(doc))
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			break
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		if m := startPattern.FindStringSubmatch(trimmed); m != nil {
+			if current != nil {
+				return nil, fmt.Errorf("line %d: status %s starts inside status %s", lineNum, m[1], current.id)
+			}
+			if seen[m[1]] {
+				return nil, fmt.Errorf("line %d: status %s appears twice", lineNum, m[1])
+			}
+			seen[m[1]] = true
+			if text.Len() > 0 {
+				segments = append(segments, segment{text: text.String()})
+				text.Reset()
+			}
+			current = &block{id: m[1], sum: m[2]}
+			continue
+		}
+		if m := endPattern.FindStringSubmatch(trimmed); m != nil {
+			if current == nil || current.id != m[1] {
+				return nil, fmt.Errorf("line %d: end of status %s without its start", lineNum, m[1])
+			}
+			current.body = body.String()
+			segments = append(segments, segment{block: current})
+			current = nil
+			body.Reset()
+			continue
+		}
+
+		if current != nil {
+			body.WriteString(line)
+		} else {
+			text.WriteString(line)
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("status %s is missing its end marker", current.id)
+	}
+	if text.Len() > 0 {
+		segments = append(segments, segment{text: text.String()})
+	}
+	return segments, nil
+}
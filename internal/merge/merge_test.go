@@ -0,0 +1,121 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+// render writes a document like the default template, with a day heading and
+// a post per ID
+func render(days map[string][]string, order []string, content map[string]string) string {
+	var b strings.Builder
+	b.WriteString("# Posts\n")
+	for _, day := range order {
+		b.WriteString("\n## " + day + "\n\n")
+		for _, id := range days[day] {
+			b.WriteString("<!-- status:" + id + " -->\n" + content[id] + "\n---\n<!-- /status:" + id + " -->\n\n")
+		}
+	}
+	return b.String()
+}
+
+func TestMergeKeepsEdits(t *testing.T) {
+	content := map[string]string{"1": "one", "2": "two", "3": "three", "4": "four"}
+	first := render(map[string][]string{"mon": {"1", "2"}}, []string{"mon"}, content)
+
+	doc, result, err := Merge(nil, []byte(first), false)
+	if err != nil {
+		t.Fatalf("first merge failed: %v", err)
+	}
+	if result.Added != 2 {
+		t.Errorf("first merge: %+v", result)
+	}
+
+	// Hand edits: commentary outside posts and a change inside post 2
+	edited := strings.Replace(string(doc), "## mon\n", "## mon\n\nMy commentary.\n", 1)
+	edited = strings.Replace(edited, "two\n", "two, with my notes\n", 1)
+
+	// Post 1 changed, 3 is new on the same day, and 4 is on a new day
+	content["1"] = "one, edited upstream"
+	second := render(map[string][]string{"mon": {"1", "2", "3"}, "tue": {"4"}}, []string{"mon", "tue"}, content)
+
+	doc, result, err = Merge([]byte(edited), []byte(second), false)
+	if err != nil {
+		t.Fatalf("second merge failed: %v", err)
+	}
+	want := Result{Added: 2, Updated: 1, Edited: 1}
+	if result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+
+	got := string(doc)
+	for _, s := range []string{"My commentary.", "one, edited upstream", "two, with my notes", "three", "## tue", "four"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("merged document is missing %q:\n%s", s, got)
+		}
+	}
+	if strings.Index(got, "three") > strings.Index(got, "## tue") {
+		t.Errorf("post 3 should be on monday:\n%s", got)
+	}
+	if strings.Count(got, "## mon") != 1 {
+		t.Errorf("monday heading repeated:\n%s", got)
+	}
+
+	// Merging the same output again changes nothing
+	again, result, err := Merge(doc, []byte(second), false)
+	if err != nil {
+		t.Fatalf("third merge failed: %v", err)
+	}
+	if string(again) != got || result.Added+result.Updated+result.Removed != 0 {
+		t.Errorf("merge wasn't idempotent: %+v\n%s", result, again)
+	}
+
+	// Without post 4, it's kept unless pruned; the edited post 2 is always kept
+	third := render(map[string][]string{"mon": {"1", "3"}}, []string{"mon"}, content)
+	if _, result, _ = Merge(doc, []byte(third), false); result.Removed != 0 {
+		t.Errorf("removed posts without prune: %+v", result)
+	}
+	pruned, result, err := Merge(doc, []byte(third), true)
+	if err != nil {
+		t.Fatalf("pruning merge failed: %v", err)
+	}
+	if result.Removed != 1 || strings.Contains(string(pruned), "four") || !strings.Contains(string(pruned), "two, with my notes") {
+		t.Errorf("prune: %+v\n%s", result, pruned)
+	}
+}
+
+func TestMergeRejectsBrokenMarkers(t *testing.T) {
+	rendered := []byte("<!-- status:1 -->\none\n<!-- /status:1 -->\n")
+	for _, old := range []string{
+		"Hand-written draft without markers\n",
+		"<!-- status:1 sum:abc -->\none\n",
+		"<!-- status:1 -->\n<!-- status:2 -->\n",
+		"one\n<!-- /status:1 -->\n",
+	} {
+		if _, _, err := Merge([]byte(old), rendered, false); err == nil {
+			t.Errorf("expected an error merging into %q", old)
+		}
+	}
+}
+
+func TestMergePrunedAll(t *testing.T) {
+	content := map[string]string{"1": "one", "2": "two"}
+	doc, _, err := Merge(nil, []byte(render(map[string][]string{"mon": {"1"}}, []string{"mon"}, content)), false)
+	if err != nil {
+		t.Fatalf("first merge failed: %v", err)
+	}
+
+	// Every old post drops out and a new one arrives
+	next := render(map[string][]string{"tue": {"2"}}, []string{"tue"}, content)
+	merged, result, err := Merge(doc, []byte(next), true)
+	if err != nil {
+		t.Fatalf("second merge failed: %v", err)
+	}
+	if result.Removed != 1 || result.Added != 1 {
+		t.Errorf("got %+v", result)
+	}
+	got := string(merged)
+	if strings.Contains(got, "one") || !strings.Contains(got, "two") || !strings.HasPrefix(got, "# Posts\n") {
+		t.Errorf("unexpected merge:\n%s", got)
+	}
+}
@@ -0,0 +1,338 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	mastodonAPI "github.com/mattn/go-mastodon"
+	"github.com/sirupsen/logrus"
+)
+
+// Source provides the statuses exported by the pipeline
+// The live API, a local archive, or a test fake can all implement it
+type Source interface {
+	// Account returns the account whose activity is being exported,
+	// or nil for sources spanning many accounts, like a hashtag timeline
+	Account(ctx context.Context) (*mastodonAPI.Account, error)
+
+	// StatusesInRange returns the account's statuses created in the time range
+	StatusesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodonAPI.Status, error)
+
+	// FavouritesInRange returns favourited statuses created in the time range
+	FavouritesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodonAPI.Status, error)
+}
+
+// HistorySource is implemented by sources that can fetch the edit history of a status
+type HistorySource interface {
+	StatusHistory(ctx context.Context, id mastodonAPI.ID) ([]*mastodonAPI.StatusHistory, error)
+}
+
+// ProfileSource is implemented by sources for a single account that can fetch
+// its pinned statuses and featured hashtags
+type ProfileSource interface {
+	PinnedStatuses(ctx context.Context) ([]*mastodonAPI.Status, error)
+	FeaturedTags(ctx context.Context) ([]templates.FeaturedTag, error)
+}
+
+// Options controls what the pipeline fetches and how posts are filtered and ordered
+type Options struct {
+	TimeRange *timerange.TimeRange
+
+	ExcludeReplies     bool
+	ExcludeBoosts      bool
+	ExcludeFavorites   bool
+	Visibility         string // Comma-separated visibilities to include, empty for all
+	PublicOnly         bool   // Exclude direct and private posts
+	IncludeEditHistory bool   // Fetch revisions of edited posts, if the source supports it
+	MinEngagement      int64  // Drop posts with fewer replies, boosts, and favourites combined
+
+	EmojiMode string // Custom emoji rendering: "shortcode", "image", or "drop"
+	SortOrder string // "asc" (oldest first, default) or "desc" (newest first)
+
+	// Log receives progress messages; nil discards them
+	Log logrus.FieldLogger
+}
+
+// Run fetches statuses and favourites from the source, applies filters,
+// and returns converted posts in the requested sort order
+func Run(ctx context.Context, src Source, opts Options) ([]templates.Post, error) {
+	log := opts.Log
+	if log == nil {
+		discard := logrus.New()
+		discard.SetOutput(io.Discard)
+		log = discard
+	}
+
+	// Fetch statuses
+	log.Info("Fetching statuses...")
+	allStatuses, err := src.StatusesInRange(ctx, opts.TimeRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch statuses: %w", err)
+	}
+
+	log.Infof("Found %d statuses in time range", len(allStatuses))
+
+	// Apply filters
+	filtered := FilterStatuses(allStatuses, opts.ExcludeReplies, opts.ExcludeBoosts, opts.Visibility, opts.PublicOnly)
+	filtered = FilterEngagement(filtered, opts.MinEngagement)
+
+	log.Infof("After filtering: %d statuses", len(filtered))
+
+	// Convert to template format
+	posts := mastodon.ConvertStatuses(filtered)
+
+	// Only attribute posts by accounts other than the one being exported
+	account, err := src.Account(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	for i, status := range filtered {
+		if account != nil && status.Account.ID == account.ID {
+			posts[i].Author = nil
+		}
+	}
+
+	// Fetch edit history for edited posts if requested
+	if history, ok := src.(HistorySource); ok && opts.IncludeEditHistory {
+		log.Info("Fetching edit history...")
+		for i, status := range filtered {
+			if status.EditedAt.IsZero() || status.Reblog != nil {
+				continue
+			}
+			versions, err := history.StatusHistory(ctx, status.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch edit history: %w", err)
+			}
+			posts[i].Revisions = mastodon.ConvertStatusHistory(versions)
+		}
+	}
+
+	// Fetch favorites unless excluded
+	if !opts.ExcludeFavorites {
+		log.Info("Fetching favorites...")
+		allFavorites, err := src.FavouritesInRange(ctx, opts.TimeRange)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch favourites: %w", err)
+		}
+
+		log.Infof("Found %d favorites in time range", len(allFavorites))
+		allFavorites = FilterEngagement(allFavorites, opts.MinEngagement)
+
+		// Convert favorites and add to posts
+		favoritePosts := mastodon.ConvertFavourites(allFavorites)
+		posts = append(posts, favoritePosts...)
+	}
+
+	// Render custom emoji shortcodes
+	if err := mastodon.ApplyEmojiMode(posts, opts.EmojiMode); err != nil {
+		return nil, err
+	}
+
+	// Sort posts based on configuration
+	sortOrder := opts.SortOrder
+	if sortOrder == "" {
+		sortOrder = "asc" // Default to oldest first
+	}
+	SortPosts(posts, sortOrder)
+
+	return posts, nil
+}
+
+// About is an account's profile, pinned posts, and featured hashtags
+type About struct {
+	Profile      *templates.Profile
+	Pinned       []templates.Post
+	FeaturedTags []templates.FeaturedTag
+}
+
+// FetchAbout fetches the source account's profile, pinned posts, and featured
+// hashtags, filtering pinned posts by visibility like other posts
+func FetchAbout(ctx context.Context, src Source, opts Options) (*About, error) {
+	account, err := src.Account(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	profileSource, ok := src.(ProfileSource)
+	if account == nil || !ok {
+		return nil, fmt.Errorf("pinned posts and featured tags need a single account as the source")
+	}
+
+	pinned, err := profileSource.PinnedStatuses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pinned statuses: %w", err)
+	}
+	pinned = FilterStatuses(pinned, false, false, opts.Visibility, opts.PublicOnly)
+
+	tags, err := profileSource.FeaturedTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch featured tags: %w", err)
+	}
+
+	about := &About{
+		Profile:      mastodon.ConvertProfile(account),
+		Pinned:       mastodon.ConvertStatuses(pinned),
+		FeaturedTags: tags,
+	}
+
+	// Pinned posts are always the account's own
+	for i := range about.Pinned {
+		about.Pinned[i].Author = nil
+	}
+
+	if err := mastodon.ApplyEmojiMode(about.Pinned, opts.EmojiMode); err != nil {
+		return nil, err
+	}
+	if err := mastodon.ApplyProfileEmojiMode(about.Profile, opts.EmojiMode); err != nil {
+		return nil, err
+	}
+
+	return about, nil
+}
+
+// AddTo adds the profile, pinned posts, and featured hashtags to template data
+func (a *About) AddTo(data *templates.TemplateData) {
+	data.Profile = a.Profile
+	data.Pinned = a.Pinned
+	data.FeaturedTags = a.FeaturedTags
+}
+
+// NewTemplateData assembles the data passed to templates for posts in a time range
+func NewTemplateData(tr *timerange.TimeRange, posts []templates.Post) *templates.TemplateData {
+	return &templates.TemplateData{
+		StartDate:   timerange.FormatDate(tr.Start),
+		EndDate:     timerange.FormatDate(tr.End),
+		Posts:       posts,
+		Days:        templates.GroupPostsByDay(posts),
+		LinkDomains: templates.GroupLinksByDomain(posts),
+		Stats:       templates.ComputeStats(posts),
+	}
+}
+
+// FilterStatuses applies visibility, reply, and boost filters to statuses
+func FilterStatuses(statuses []*mastodonAPI.Status, excludeReplies, excludeBoosts bool, visibilityFilter string, publicOnly bool) []*mastodonAPI.Status {
+	filtered := []*mastodonAPI.Status{}
+
+	// Parse visibility filter
+	visibilities := map[string]bool{}
+	if visibilityFilter != "" {
+		for _, v := range strings.Split(visibilityFilter, ",") {
+			visibilities[strings.TrimSpace(v)] = true
+		}
+	}
+
+	for _, status := range statuses {
+		// Filter replies
+		if excludeReplies && status.InReplyToID != nil {
+			continue
+		}
+
+		// Filter boosts
+		if excludeBoosts && status.Reblog != nil {
+			continue
+		}
+
+		// Filter by public_only (exclude direct and private posts)
+		if publicOnly && (status.Visibility == "direct" || status.Visibility == "private") {
+			continue
+		}
+
+		// Filter by visibility
+		if len(visibilities) > 0 && !visibilities[string(status.Visibility)] {
+			continue
+		}
+
+		filtered = append(filtered, status)
+	}
+
+	return filtered
+}
+
+// FilterPosts applies the time range, visibility, reply, boost, and favorite
+// filters of opts to posts that were already converted, such as cached
+// template data, and sorts them
+// Engagement can't be filtered this way, since boosts don't carry the counts
+// of the original post
+func FilterPosts(posts []templates.Post, opts Options) []templates.Post {
+	visibilities := map[string]bool{}
+	if opts.Visibility != "" {
+		for _, v := range strings.Split(opts.Visibility, ",") {
+			visibilities[strings.TrimSpace(v)] = true
+		}
+	}
+
+	filtered := []templates.Post{}
+	for _, post := range posts {
+		if opts.TimeRange != nil && (post.CreatedAt.Before(opts.TimeRange.Start) || post.CreatedAt.After(opts.TimeRange.End)) {
+			continue
+		}
+
+		if post.IsFavorited {
+			if opts.ExcludeFavorites {
+				continue
+			}
+			filtered = append(filtered, post)
+			continue
+		}
+
+		if opts.ExcludeReplies && post.IsReply {
+			continue
+		}
+		if opts.ExcludeBoosts && post.IsBoost {
+			continue
+		}
+		if opts.PublicOnly && (post.Visibility == "direct" || post.Visibility == "private") {
+			continue
+		}
+		if len(visibilities) > 0 && !visibilities[post.Visibility] {
+			continue
+		}
+
+		filtered = append(filtered, post)
+	}
+
+	sortOrder := opts.SortOrder
+	if sortOrder == "" {
+		sortOrder = "asc"
+	}
+	SortPosts(filtered, sortOrder)
+
+	return filtered
+}
+
+// FilterEngagement drops statuses with fewer than threshold replies, boosts, and
+// favourites combined, counting the original post for boosts
+func FilterEngagement(statuses []*mastodonAPI.Status, threshold int64) []*mastodonAPI.Status {
+	if threshold <= 0 {
+		return statuses
+	}
+
+	filtered := []*mastodonAPI.Status{}
+	for _, status := range statuses {
+		counted := status
+		if status.Reblog != nil {
+			counted = status.Reblog
+		}
+		if counted.RepliesCount+counted.ReblogsCount+counted.FavouritesCount >= threshold {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered
+}
+
+// SortPosts sorts posts by creation time
+// sortOrder: "asc" for oldest first (forward chronological), "desc" for newest first
+func SortPosts(posts []templates.Post, sortOrder string) {
+	sort.Slice(posts, func(i, j int) bool {
+		if sortOrder == "desc" {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+		// Default to "asc" - oldest first
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+}
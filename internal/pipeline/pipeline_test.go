@@ -0,0 +1,215 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	mastodonAPI "github.com/mattn/go-mastodon"
+)
+
+// memorySource serves statuses from memory, filtering by time range like the live API source
+type memorySource struct {
+	statuses   []*mastodonAPI.Status
+	favourites []*mastodonAPI.Status
+	histories  map[mastodonAPI.ID][]*mastodonAPI.StatusHistory
+}
+
+func (s *memorySource) Account(ctx context.Context) (*mastodonAPI.Account, error) {
+	return &mastodonAPI.Account{ID: "1", Username: "alice"}, nil
+}
+
+func (s *memorySource) StatusesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodonAPI.Status, error) {
+	return inRange(s.statuses, tr), nil
+}
+
+func (s *memorySource) FavouritesInRange(ctx context.Context, tr *timerange.TimeRange) ([]*mastodonAPI.Status, error) {
+	return inRange(s.favourites, tr), nil
+}
+
+func (s *memorySource) StatusHistory(ctx context.Context, id mastodonAPI.ID) ([]*mastodonAPI.StatusHistory, error) {
+	return s.histories[id], nil
+}
+
+func inRange(statuses []*mastodonAPI.Status, tr *timerange.TimeRange) []*mastodonAPI.Status {
+	var result []*mastodonAPI.Status
+	for _, status := range statuses {
+		if !status.CreatedAt.Before(tr.Start) && !status.CreatedAt.After(tr.End) {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+var day = time.Date(2025, 11, 5, 0, 0, 0, 0, time.UTC)
+
+func newSource() *memorySource {
+	original := &mastodonAPI.Status{ID: "900", CreatedAt: day, Content: "<p>original</p>"}
+	return &memorySource{
+		statuses: []*mastodonAPI.Status{
+			{ID: "5", CreatedAt: day.Add(5 * time.Hour), Content: "<p>edited</p>", Visibility: "public", EditedAt: day.Add(6 * time.Hour)},
+			{ID: "4", CreatedAt: day.Add(4 * time.Hour), Visibility: "private"},
+			{ID: "3", CreatedAt: day.Add(3 * time.Hour), Visibility: "public", Reblog: original},
+			{ID: "2", CreatedAt: day.Add(2 * time.Hour), Visibility: "unlisted", InReplyToID: "900"},
+			{ID: "1", CreatedAt: day.Add(-48 * time.Hour), Visibility: "public"},
+		},
+		favourites: []*mastodonAPI.Status{
+			{ID: "20", CreatedAt: day.Add(1 * time.Hour), Content: "<p>fave</p>"},
+		},
+		histories: map[mastodonAPI.ID][]*mastodonAPI.StatusHistory{
+			"5": {
+				{Content: "<p>first</p>", CreatedAt: day.Add(5 * time.Hour)},
+				{Content: "<p>edited</p>", CreatedAt: day.Add(6 * time.Hour)},
+			},
+		},
+	}
+}
+
+func postIDs(t *testing.T, opts Options) []string {
+	t.Helper()
+	opts.TimeRange = &timerange.TimeRange{Start: day, End: day.Add(24 * time.Hour)}
+
+	posts, err := Run(context.Background(), newSource(), opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	return ids
+}
+
+func TestRunFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "public only", opts: Options{PublicOnly: true}, want: []string{"20", "2", "3", "5"}},
+		{name: "all visibilities", opts: Options{}, want: []string{"20", "2", "3", "4", "5"}},
+		{name: "exclude replies and boosts", opts: Options{PublicOnly: true, ExcludeReplies: true, ExcludeBoosts: true}, want: []string{"20", "5"}},
+		{name: "exclude favorites", opts: Options{PublicOnly: true, ExcludeFavorites: true}, want: []string{"2", "3", "5"}},
+		{name: "visibility filter", opts: Options{Visibility: "unlisted, private"}, want: []string{"20", "2", "4"}},
+		{name: "newest first", opts: Options{PublicOnly: true, SortOrder: "desc"}, want: []string{"5", "3", "2", "20"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := postIDs(t, tt.opts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got posts %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got posts %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunEditHistory(t *testing.T) {
+	tr := &timerange.TimeRange{Start: day, End: day.Add(24 * time.Hour)}
+
+	posts, err := Run(context.Background(), newSource(), Options{TimeRange: tr, ExcludeFavorites: true, IncludeEditHistory: true, SortOrder: "desc"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	edited := posts[0]
+	if edited.ID != "5" || edited.FormattedEditedAt == "" {
+		t.Fatalf("expected edited post 5 first, got %+v", edited)
+	}
+	if len(edited.Revisions) != 2 || edited.Revisions[0].Content != "first" {
+		t.Errorf("unexpected revisions: %+v", edited.Revisions)
+	}
+	for _, post := range posts[1:] {
+		if len(post.Revisions) != 0 {
+			t.Errorf("post %s should have no revisions", post.ID)
+		}
+	}
+}
+
+func TestRunRejectsUnknownEmojiMode(t *testing.T) {
+	tr := &timerange.TimeRange{Start: day, End: day.Add(24 * time.Hour)}
+	if _, err := Run(context.Background(), newSource(), Options{TimeRange: tr, EmojiMode: "sparkles"}); err == nil {
+		t.Error("expected an error for an unknown emoji mode")
+	}
+}
+
+func TestFilterEngagement(t *testing.T) {
+	popular := &mastodonAPI.Status{ID: "900", RepliesCount: 2, ReblogsCount: 3, FavouritesCount: 5}
+	statuses := []*mastodonAPI.Status{
+		{ID: "1", FavouritesCount: 10},
+		{ID: "2", FavouritesCount: 9},
+		{ID: "3", Reblog: popular},
+	}
+
+	got := FilterEngagement(statuses, 10)
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "3" {
+		t.Errorf("expected statuses 1 and 3 (boost counts the original), got %v", got)
+	}
+	if len(FilterEngagement(statuses, 0)) != 3 {
+		t.Error("expected no filtering without a threshold")
+	}
+}
+
+func TestRunAttributesOtherAuthors(t *testing.T) {
+	src := newSource()
+	src.statuses[0].Account = mastodonAPI.Account{ID: "1", Username: "alice"}
+	src.statuses[2].Account = mastodonAPI.Account{ID: "2", Acct: "bob@other.example"}
+	tr := &timerange.TimeRange{Start: day, End: day.Add(24 * time.Hour)}
+
+	posts, err := Run(context.Background(), src, Options{TimeRange: tr, ExcludeFavorites: true, SortOrder: "desc"})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for _, post := range posts {
+		switch post.ID {
+		case "5":
+			if post.Author != nil {
+				t.Errorf("own post should have no author, got %+v", post.Author)
+			}
+		case "3":
+			if post.Author == nil || post.Author.Acct != "bob@other.example" {
+				t.Errorf("expected bob as author, got %+v", post.Author)
+			}
+		}
+	}
+}
+
+func TestFilterPostsMatchesRun(t *testing.T) {
+	tr := &timerange.TimeRange{Start: day, End: day.Add(24 * time.Hour)}
+
+	// Convert everything once, as a cache would, then filter the posts
+	all, err := Run(context.Background(), newSource(), Options{TimeRange: &timerange.TimeRange{Start: day.AddDate(0, 0, -7), End: day.AddDate(0, 0, 7)}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for _, opts := range []Options{
+		{PublicOnly: true},
+		{ExcludeReplies: true, ExcludeBoosts: true, SortOrder: "desc"},
+		{ExcludeFavorites: true, Visibility: "unlisted,private"},
+	} {
+		opts.TimeRange = tr
+		want, err := Run(context.Background(), newSource(), opts)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		got := FilterPosts(all, opts)
+
+		if len(got) != len(want) {
+			t.Fatalf("FilterPosts(%+v) returned %d posts, Run returned %d", opts, len(got), len(want))
+		}
+		for i := range got {
+			if got[i].ID != want[i].ID {
+				t.Errorf("FilterPosts(%+v)[%d] = %s, want %s", opts, i, got[i].ID, want[i].ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package postcsv writes posts as CSV rows for spreadsheet analysis
+package postcsv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// column extracts one field from a post
+type column func(post templates.Post) string
+
+// columns maps column names to their values, in the default order
+var columns = []struct {
+	name  string
+	value column
+}{
+	{"id", func(p templates.Post) string { return p.ID }},
+	{"created_at", func(p templates.Post) string { return p.CreatedAt.UTC().Format(time.RFC3339) }},
+	{"url", func(p templates.Post) string { return p.URL }},
+	{"type", postType},
+	{"visibility", func(p templates.Post) string { return p.Visibility }},
+	{"content", func(p templates.Post) string { return shown(p).Content }},
+	{"cw", func(p templates.Post) string { return shown(p).ContentWarning }},
+	{"replies", func(p templates.Post) string { return strconv.FormatInt(p.RepliesCount, 10) }},
+	{"boosts", func(p templates.Post) string { return strconv.FormatInt(p.ReblogsCount, 10) }},
+	{"favourites", func(p templates.Post) string { return strconv.FormatInt(p.FavouritesCount, 10) }},
+	{"engagement", func(p templates.Post) string {
+		return strconv.FormatInt(p.RepliesCount+p.ReblogsCount+p.FavouritesCount, 10)
+	}},
+	{"media", func(p templates.Post) string { return strconv.Itoa(len(shown(p).MediaAttachments)) }},
+	{"tags", func(p templates.Post) string { return strings.Join(shown(p).Tags, " ") }},
+	{"author", postAuthor},
+}
+
+// textColumns hold text anyone could have written, which Excel mode keeps
+// from being read as a formula
+var textColumns = map[string]bool{"content": true, "cw": true, "author": true}
+
+// DefaultColumns returns the names of all columns, in their default order
+func DefaultColumns() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// byteOrderMark makes Excel read the file as UTF-8
+const byteOrderMark = "\ufeff"
+
+// formulaStarts are the first characters that make Excel read a cell as a formula
+const formulaStarts = "=+-@\t\r"
+
+// Write writes a header row and a row per post with the named columns, quoted
+// as RFC 4180 requires with CRLF line endings
+// For Excel, the file starts with a UTF-8 byte order mark, and free text that
+// Excel would read as a formula, such as a reply starting with @, gets a leading '
+func Write(w io.Writer, posts []templates.Post, names []string, excel bool) error {
+	if len(names) == 0 {
+		names = DefaultColumns()
+	}
+	values := make([]column, len(names))
+	for i, name := range names {
+		value, err := lookup(name)
+		if err != nil {
+			return err
+		}
+		if excel && textColumns[name] {
+			value = escapeFormula(value)
+		}
+		values[i] = value
+	}
+
+	if excel {
+		if _, err := io.WriteString(w, byteOrderMark); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	if err := cw.Write(names); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, post := range posts {
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = value(post)
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// lookup returns the column with a name
+func lookup(name string) (column, error) {
+	for _, c := range columns {
+		if c.name == name {
+			return c.value, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown CSV column %q (available: %s)", name, strings.Join(DefaultColumns(), ", "))
+}
+
+// escapeFormula wraps a column to prefix values Excel would read as a formula with '
+func escapeFormula(value column) column {
+	return func(p templates.Post) string {
+		s := value(p)
+		if s != "" && strings.ContainsRune(formulaStarts, rune(s[0])) {
+			return "'" + s
+		}
+		return s
+	}
+}
+
+// shownPost holds the fields that come from the original post for boosts and favorites
+type shownPost struct {
+	Content          string
+	ContentWarning   string
+	MediaAttachments []templates.MediaAttachment
+	Tags             []string
+}
+
+// shown returns the content a reader sees: the original post's for boosts and favorites
+func shown(p templates.Post) shownPost {
+	if o := p.OriginalPost; o != nil && (p.IsBoost || p.IsFavorited) {
+		return shownPost{o.Content, o.ContentWarning, o.MediaAttachments, o.Tags}
+	}
+	return shownPost{p.Content, p.ContentWarning, p.MediaAttachments, p.Tags}
+}
+
+// postType returns "post", "reply", "boost", or "favorite"
+func postType(p templates.Post) string {
+	switch {
+	case p.IsFavorited:
+		return "favorite"
+	case p.IsBoost:
+		return "boost"
+	case p.IsReply:
+		return "reply"
+	default:
+		return "post"
+	}
+}
+
+// postAuthor returns the acct of whoever wrote the content: the original
+// author for boosts and favorites, empty for my own posts
+func postAuthor(p templates.Post) string {
+	if o := p.OriginalPost; o != nil && (p.IsBoost || p.IsFavorited) {
+		return o.AuthorAcct
+	}
+	if p.Author != nil {
+		return p.Author.Acct
+	}
+	return ""
+}
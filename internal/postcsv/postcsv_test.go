@@ -0,0 +1,68 @@
+package postcsv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+func TestWriteQuotesAndBOM(t *testing.T) {
+	posts := []templates.Post{
+		{
+			ID:        "1",
+			CreatedAt: time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC),
+			Content:   "Hello, \"world\"\n\nSee *the docs* at https://example.com/docs",
+			Tags:      []string{"go", "csv"},
+		},
+		{
+			ID:      "2",
+			IsBoost: true,
+			OriginalPost: &templates.OriginalPost{
+				AuthorAcct: "bob@example.social",
+				Content:    "Boosted **post**",
+			},
+		},
+		{
+			ID:             "3",
+			IsReply:        true,
+			Content:        "@bob =1+1 is two",
+			ContentWarning: "-maths",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, posts, []string{"id", "type", "content", "cw", "tags", "author"}, true); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	want := "\ufeff" +
+		"id,type,content,cw,tags,author\r\n" +
+		"1,post,\"Hello, \"\"world\"\"\r\n\r\nSee *the docs* at https://example.com/docs\",,go csv,\r\n" +
+		"2,boost,Boosted **post**,,,bob@example.social\r\n" +
+		"3,reply,'@bob =1+1 is two,'-maths,,\r\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteKeepsFormulaTextWithoutBOM(t *testing.T) {
+	posts := []templates.Post{{ID: "1", Content: "@bob =1+1"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, posts, []string{"id", "content"}, false); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := "id,content\r\n1,@bob =1+1\r\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteUnknownColumn(t *testing.T) {
+	err := Write(&bytes.Buffer{}, nil, []string{"id", "nope"}, false)
+	if err == nil || !strings.Contains(err.Error(), `"nope"`) {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
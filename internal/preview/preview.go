@@ -0,0 +1,277 @@
+// Package preview serves rendered templates over template data in a browser,
+// reloading the page when the template or configuration changes
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/pipeline"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Settings are the template and the defaults used when a request doesn't override them
+type Settings struct {
+	// Template is a built-in template name or the path to a template file
+	Template string
+
+	// Options holds the default time range, filters, and sort order
+	Options pipeline.Options
+}
+
+// Server renders the template over fixed template data
+//
+// Routes:
+//   - /: the rendered Markdown converted to HTML, with live reload
+//   - /raw: the rendered Markdown
+//   - /events: server-sent events announcing reloads
+//
+// Query parameters override the defaults: since, start, end, sort-order,
+// public-only, exclude-replies, exclude-boosts, exclude-favorites, and visibility
+type Server struct {
+	data     *templates.TemplateData
+	markdown goldmark.Markdown
+
+	mu       sync.RWMutex
+	settings Settings
+	clients  map[chan struct{}]bool
+}
+
+// New returns a server previewing data with the given settings
+// Posts are filtered again for each request, while the profile, pinned posts,
+// and featured hashtags are passed through as they are
+func New(data *templates.TemplateData, settings Settings) *Server {
+	return &Server{
+		data:     data,
+		markdown: goldmark.New(goldmark.WithExtensions(extension.GFM)),
+		settings: settings,
+		clients:  map[chan struct{}]bool{},
+	}
+}
+
+// Update replaces the settings and reloads connected browsers
+func (s *Server) Update(settings Settings) {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+	s.Reload()
+}
+
+// Reload tells connected browsers to reload the page
+func (s *Server) Reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- struct{}{}:
+		default: // A reload is already pending
+		}
+	}
+}
+
+// ServeHTTP routes requests to the preview pages
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		s.serveHTML(w, r)
+	case "/raw":
+		s.serveRaw(w, r)
+	case "/events":
+		s.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveRaw writes the rendered Markdown
+func (s *Server) serveRaw(w http.ResponseWriter, r *http.Request) {
+	markdown, err := s.render(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write(markdown)
+}
+
+// serveHTML writes the rendered Markdown converted to HTML, or the error from
+// rendering it, in a page that reloads when the server says so
+func (s *Server) serveHTML(w http.ResponseWriter, r *http.Request) {
+	page := pageData{RawURL: "/raw"}
+	if r.URL.RawQuery != "" {
+		page.RawURL += "?" + r.URL.RawQuery
+	}
+
+	status := http.StatusOK
+	markdown, err := s.render(r.URL.Query())
+	if err == nil {
+		var body bytes.Buffer
+		err = s.markdown.Convert(markdown, &body)
+		page.Body = template.HTML(body.String())
+	}
+	if err != nil {
+		// Keep the reload script on error pages, so fixing the template recovers
+		status = http.StatusInternalServerError
+		page.Error = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = pageTemplate.Execute(w, page)
+}
+
+// serveEvents streams a "reload" event whenever Reload is called
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[client] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-client:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// render filters the posts with the query's overrides and renders the template,
+// loading it anew so edits show up
+func (s *Server) render(query url.Values) ([]byte, error) {
+	s.mu.RLock()
+	settings := s.settings
+	s.mu.RUnlock()
+
+	opts, err := applyQuery(settings.Options, query)
+	if err != nil {
+		return nil, err
+	}
+
+	renderer, err := templates.NewRenderer(settings.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := pipeline.FilterPosts(s.data.Posts, opts)
+	var data *templates.TemplateData
+	if opts.TimeRange != nil {
+		data = pipeline.NewTemplateData(opts.TimeRange, posts)
+	} else {
+		// Without a range, keep the dates the data was fetched for
+		data = pipeline.NewTemplateData(&timerange.TimeRange{}, posts)
+		data.StartDate, data.EndDate = s.data.StartDate, s.data.EndDate
+	}
+	data.Profile = s.data.Profile
+	data.Pinned = s.data.Pinned
+	data.FeaturedTags = s.data.FeaturedTags
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// applyQuery overrides options with query parameters named like the fetch flags
+func applyQuery(opts pipeline.Options, query url.Values) (pipeline.Options, error) {
+	since, start, end := query.Get("since"), query.Get("start"), query.Get("end")
+	if since != "" || start != "" || end != "" {
+		tr, err := timerange.Parse(since, start, end)
+		if err != nil {
+			return opts, fmt.Errorf("invalid time range: %w", err)
+		}
+		opts.TimeRange = tr
+	}
+
+	if v := query.Get("sort-order"); v != "" {
+		opts.SortOrder = v
+	}
+	if query.Has("visibility") {
+		opts.Visibility = query.Get("visibility")
+	}
+
+	flags := []struct {
+		name  string
+		value *bool
+	}{
+		{"public-only", &opts.PublicOnly},
+		{"exclude-replies", &opts.ExcludeReplies},
+		{"exclude-boosts", &opts.ExcludeBoosts},
+		{"exclude-favorites", &opts.ExcludeFavorites},
+	}
+	for _, flag := range flags {
+		if !query.Has(flag.name) {
+			continue
+		}
+		// A bare parameter, like ?exclude-replies, means true
+		v := query.Get(flag.name)
+		if v == "" {
+			v = "true"
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s: %w", flag.name, err)
+		}
+		*flag.value = b
+	}
+
+	return opts, nil
+}
+
+// pageData is passed to the HTML page template
+type pageData struct {
+	RawURL string
+	Body   template.HTML
+	Error  string
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>mastodon-to-markdown preview</title>
+<style>
+body { max-width: 48em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; }
+nav { font-size: 0.9em; color: #666; border-bottom: 1px solid #ddd; margin-bottom: 1em; }
+pre.error { background: #fee; border: 1px solid #c00; padding: 1em; white-space: pre-wrap; }
+img { max-width: 100%; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ddd; padding: 0.25em 0.5em; }
+</style>
+</head>
+<body>
+<nav>Preview &middot; <a href="{{.RawURL}}">Markdown</a></nav>
+{{if .Error}}<pre class="error">{{.Error}}</pre>{{else}}{{.Body}}{{end}}
+<script>
+new EventSource("/events").onmessage = function () { location.reload(); };
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,106 @@
+package preview
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/pipeline"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+func testData() *templates.TemplateData {
+	posts := []templates.Post{
+		{ID: "1", Content: "first", Visibility: "public", CreatedAt: time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC)},
+		{ID: "2", Content: "reply", Visibility: "public", IsReply: true, CreatedAt: time.Date(2025, 11, 4, 9, 0, 0, 0, time.UTC)},
+		{ID: "3", Content: "secret", Visibility: "private", CreatedAt: time.Date(2025, 11, 5, 9, 0, 0, 0, time.UTC)},
+	}
+	return &templates.TemplateData{StartDate: "2025-11-03", EndDate: "2025-11-05", Posts: posts}
+}
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "preview.md")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	return path
+}
+
+func get(t *testing.T, server *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestServerQueryOverrides(t *testing.T) {
+	tmpl := writeTemplate(t, "{{.StartDate}}..{{.EndDate}}:{{range .Posts}} {{.Content}}{{end}}\n")
+	server := httptest.NewServer(New(testData(), Settings{
+		Template: tmpl,
+		Options:  pipeline.Options{PublicOnly: true},
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "2025-11-03..2025-11-05: first reply\n"},
+		{"?public-only=false&sort-order=desc", "2025-11-03..2025-11-05: secret reply first\n"},
+		{"?exclude-replies", "2025-11-03..2025-11-05: first\n"},
+		{"?start=2025-11-04&end=2025-11-05", "2025-11-04..2025-11-05: reply\n"},
+	}
+	for _, tt := range tests {
+		status, body := get(t, server, "/raw"+tt.query)
+		if status != http.StatusOK || body != tt.want {
+			t.Errorf("/raw%s = %d %q, want %q", tt.query, status, body, tt.want)
+		}
+	}
+
+	status, body := get(t, server, "/?exclude-replies")
+	if status != http.StatusOK || !strings.Contains(body, "<p>2025-11-03..2025-11-05: first</p>") {
+		t.Errorf("/ did not render HTML: %d %s", status, body)
+	}
+	if !strings.Contains(body, `new EventSource("/events")`) {
+		t.Errorf("/ is missing the reload script")
+	}
+
+	status, body = get(t, server, "/?exclude-replies=maybe")
+	if status != http.StatusInternalServerError || !strings.Contains(body, "invalid exclude-replies") {
+		t.Errorf("bad query = %d %s, want an error page", status, body)
+	}
+}
+
+func TestServerReload(t *testing.T) {
+	preview := New(testData(), Settings{Template: writeTemplate(t, "old\n")})
+	server := httptest.NewServer(preview)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	preview.Update(Settings{Template: writeTemplate(t, "new\n")})
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != "data: reload\n" {
+		t.Fatalf("event = %q, %v; want a reload", line, err)
+	}
+
+	if _, body := get(t, server, "/raw"); body != "new\n" {
+		t.Errorf("/raw after update = %q, want the new template", body)
+	}
+}
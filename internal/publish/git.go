@@ -0,0 +1,151 @@
+// Package publish sends rendered output to where it's published, such as a
+// static site's git repository
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitOptions describes a file to commit to a git working tree
+type GitOptions struct {
+	Path    string // File path, relative to the working tree
+	Content []byte
+	Message string // Commit message
+
+	Push   bool   // Push the commit after creating it
+	Remote string // Remote to push to, "origin" if empty
+}
+
+// Git publishes files to a git working tree using the git command
+type Git struct {
+	Dir string // Working tree path
+}
+
+// Publish writes the file, stages it, and commits it, then pushes if asked
+// It refuses when the tree has uncommitted changes other than to the file itself,
+// so unrelated work never ends up in the commit
+// Returns false without committing if the file is unchanged
+func (g *Git) Publish(ctx context.Context, opts GitOptions) (bool, error) {
+	if opts.Path == "" {
+		return false, fmt.Errorf("no file path to publish")
+	}
+
+	top, err := g.git(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false, err
+	}
+	target, err := g.relativePath(strings.TrimSpace(top), opts.Path)
+	if err != nil {
+		return false, err
+	}
+
+	dirty, err := g.changedPaths(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, path := range dirty {
+		if path != target {
+			return false, fmt.Errorf("working tree %s has uncommitted changes to %s; commit or stash them first", g.Dir, path)
+		}
+	}
+
+	file := filepath.Join(g.Dir, opts.Path)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create directory for %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, opts.Content, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	if _, err := g.git(ctx, "add", "--", opts.Path); err != nil {
+		return false, err
+	}
+	staged, err := g.git(ctx, "diff", "--cached", "--name-only")
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(staged) == "" {
+		return false, nil
+	}
+
+	if _, err := g.git(ctx, "commit", "--quiet", "--message", opts.Message); err != nil {
+		return false, err
+	}
+
+	if opts.Push {
+		remote := opts.Remote
+		if remote == "" {
+			remote = "origin"
+		}
+		if _, err := g.git(ctx, "push", "--quiet", remote, "HEAD"); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// relativePath returns path, relative to the working tree directory, as git
+// reports it: relative to the top of the repository, with forward slashes
+func (g *Git) relativePath(top, path string) (string, error) {
+	dir, err := filepath.Abs(g.Dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", g.Dir, err)
+	}
+	// git reports the top level with symlinks resolved
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	rel, err := filepath.Rel(top, filepath.Join(dir, path))
+	if err != nil || rel == "." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || rel == ".." {
+		return "", fmt.Errorf("path %s is outside the working tree %s", path, top)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// changedPaths lists paths with staged, unstaged, or untracked changes
+func (g *Git) changedPaths(ctx context.Context) ([]string, error) {
+	out, err := g.git(ctx, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		// Renames and copies are followed by the original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+			if i < len(entries) {
+				paths = append(paths, entries[i])
+			}
+		}
+	}
+	return paths, nil
+}
+
+// git runs a git command in the working tree and returns its output
+func (g *Git) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
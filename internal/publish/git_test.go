@@ -0,0 +1,113 @@
+package publish
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run runs a command in dir, failing the test on error
+func run(t *testing.T, dir string, name string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s %v failed: %v\n%s", name, args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cloneBare creates a bare repository with one commit and returns it and a clone
+func cloneBare(t *testing.T) (bare, work string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	root := t.TempDir()
+	bare = filepath.Join(root, "site.git")
+	work = filepath.Join(root, "site")
+	run(t, root, "git", "init", "--quiet", "--bare", bare)
+	run(t, root, "git", "clone", "--quiet", bare, work)
+	if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("site\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, work, "git", "add", "README.md")
+	run(t, work, "git", "commit", "--quiet", "-m", "Initial commit")
+	run(t, work, "git", "push", "--quiet", "origin", "HEAD")
+	return bare, work
+}
+
+func TestGitPublish(t *testing.T) {
+	bare, work := cloneBare(t)
+	git := &Git{Dir: work}
+	ctx := context.Background()
+
+	opts := GitOptions{
+		Path:    "content/posts/digest.md",
+		Content: []byte("# Digest\n"),
+		Message: "Mastodon digest 2025-11-03–2025-11-10",
+		Push:    true,
+	}
+	committed, err := git.Publish(ctx, opts)
+	if err != nil || !committed {
+		t.Fatalf("Publish = %v, %v; want a commit", committed, err)
+	}
+	if got := run(t, bare, "git", "log", "-1", "--format=%s"); got != opts.Message {
+		t.Errorf("pushed commit message = %q, want %q", got, opts.Message)
+	}
+	if got := run(t, bare, "git", "show", "HEAD:content/posts/digest.md"); got != "# Digest" {
+		t.Errorf("pushed file = %q", got)
+	}
+
+	// Publishing the same content again is a no-op
+	committed, err = git.Publish(ctx, opts)
+	if err != nil || committed {
+		t.Errorf("republish = %v, %v; want no commit", committed, err)
+	}
+
+	// Changes to the published file itself are fine, since it is overwritten
+	if err := os.WriteFile(filepath.Join(work, opts.Path), []byte("local edit\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts.Content = []byte("# Digest, updated\n")
+	opts.Push = false
+	if committed, err := git.Publish(ctx, opts); err != nil || !committed {
+		t.Fatalf("Publish over an edited file = %v, %v; want a commit", committed, err)
+	}
+	if pushed, parent := run(t, bare, "git", "rev-parse", "HEAD"), run(t, work, "git", "rev-parse", "HEAD~1"); pushed != parent {
+		t.Errorf("bare repository is at %s, want %s since the last commit wasn't pushed", pushed, parent)
+	}
+}
+
+func TestGitPublishRefusesUnrelatedChanges(t *testing.T) {
+	_, work := cloneBare(t)
+	git := &Git{Dir: work}
+
+	if err := os.WriteFile(filepath.Join(work, "draft.md"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := git.Publish(context.Background(), GitOptions{Path: "digest.md", Content: []byte("x\n"), Message: "digest"})
+	if err == nil || !strings.Contains(err.Error(), "draft.md") {
+		t.Fatalf("Publish with an untracked file = %v, want a refusal naming it", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(work, "digest.md")); statErr == nil {
+		t.Errorf("refused publish still wrote the file")
+	}
+
+	_, err = git.Publish(context.Background(), GitOptions{Path: "../outside.md", Content: []byte("x\n"), Message: "digest"})
+	if err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Errorf("Publish outside the tree = %v, want an error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// MicropubEntry is an h-entry to create at a Micropub endpoint
+type MicropubEntry struct {
+	Name       string // Title, empty for notes
+	Content    string
+	Published  time.Time // Zero to let the server decide
+	Categories []string
+	Photos     []string // Photo URLs
+}
+
+// Micropub creates entries at a Micropub endpoint with a bearer token
+type Micropub struct {
+	Client   *http.Client
+	Endpoint string
+	Token    string
+}
+
+// NewMicropub returns a client for a Micropub endpoint
+func NewMicropub(endpoint, token string) *Micropub {
+	return &Micropub{
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		Endpoint: endpoint,
+		Token:    token,
+	}
+}
+
+// Create posts an entry as a form-encoded create request and returns the URL
+// of the new entry from the Location header, which may be empty for servers
+// that accept entries asynchronously
+func (m *Micropub) Create(ctx context.Context, entry MicropubEntry) (string, error) {
+	form := url.Values{}
+	form.Set("h", "entry")
+	form.Set("content", entry.Content)
+	if entry.Name != "" {
+		form.Set("name", entry.Name)
+	}
+	if !entry.Published.IsZero() {
+		form.Set("published", entry.Published.Format(time.RFC3339))
+	}
+	for _, category := range entry.Categories {
+		form.Add("category[]", category)
+	}
+	for _, photo := range entry.Photos {
+		form.Add("photo[]", photo)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create micropub request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if m.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.Token)
+	}
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to post to %s: %w", m.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("micropub endpoint %s returned %s: %s", m.Endpoint, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp.Header.Get("Location"), nil
+}
+
+// DigestEntry is a single entry for a whole rendered digest, categorized with
+// every hashtag used and illustrated with the photos in my own posts
+func DigestEntry(name string, content []byte, published time.Time, posts []templates.Post) MicropubEntry {
+	categories := map[string]bool{}
+	photos := []string{}
+	for _, post := range posts {
+		for _, tag := range post.Tags {
+			categories[strings.ToLower(tag)] = true
+		}
+		if !post.IsBoost && !post.IsFavorited {
+			photos = append(photos, postPhotos(post)...)
+		}
+	}
+
+	entry := MicropubEntry{
+		Name:       name,
+		Content:    string(content),
+		Published:  published,
+		Categories: []string{},
+		Photos:     photos,
+	}
+	for category := range categories {
+		entry.Categories = append(entry.Categories, category)
+	}
+	sort.Strings(entry.Categories)
+	return entry
+}
+
+// PostEntry is an entry for one of my own posts, published at the time of the post
+func PostEntry(post templates.Post, content []byte) MicropubEntry {
+	categories := []string{}
+	for _, tag := range post.Tags {
+		categories = append(categories, strings.ToLower(tag))
+	}
+	return MicropubEntry{
+		Content:    string(content),
+		Published:  post.CreatedAt,
+		Categories: categories,
+		Photos:     postPhotos(post),
+	}
+}
+
+// postPhotos returns the URLs of a post's image attachments
+func postPhotos(post templates.Post) []string {
+	photos := []string{}
+	for _, media := range post.MediaAttachments {
+		if media.Type == "image" {
+			photos = append(photos, media.URL)
+		}
+	}
+	return photos
+}
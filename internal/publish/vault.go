@@ -0,0 +1,125 @@
+package publish
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Markers around the part of a daily note that publishing manages
+const (
+	VaultStart = "<!-- mastodon:start -->"
+	VaultEnd   = "<!-- mastodon:end -->"
+)
+
+// Vault publishes to daily notes in a notes vault, such as Obsidian or Logseq
+type Vault struct {
+	Dir        string // Folder of daily notes
+	DateFormat string // Go time layout of note file names, "2006-01-02" if empty
+}
+
+// NotePath returns the path of the daily note for a date (e.g., "2025-11-11")
+func (v *Vault) NotePath(date string) (string, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	layout := v.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+	return filepath.Join(v.Dir, day.Format(layout)+".md"), nil
+}
+
+// Publish writes content into the managed region of the daily note for a
+// date, creating the note if needed; the rest of the note is left alone
+// Returns false without writing if the region is unchanged
+func (v *Vault) Publish(date string, content []byte) (bool, error) {
+	path, err := v.NotePath(date)
+	if err != nil {
+		return false, err
+	}
+
+	note, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read note %s: %w", path, err)
+	}
+
+	updated, err := ReplaceRegion(note, content)
+	if err != nil {
+		return false, fmt.Errorf("failed to update note %s: %w", path, err)
+	}
+	if bytes.Equal(updated, note) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	// Write to a temporary file first, so an interrupted write never truncates a note
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return false, fmt.Errorf("failed to write note %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(updated); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("failed to write note %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("failed to write note %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("failed to write note %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, fmt.Errorf("failed to write note %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// ReplaceRegion returns note with the text between the markers replaced by
+// content, or with a new region appended if the note has none
+// Content can't contain the markers, or the region would end early next time
+func ReplaceRegion(note, content []byte) ([]byte, error) {
+	for _, marker := range []string{VaultStart, VaultEnd} {
+		if bytes.Contains(content, []byte(marker)) {
+			return nil, fmt.Errorf("content contains the %s marker", marker)
+		}
+	}
+	region := []byte(VaultStart + "\n" + string(bytes.TrimRight(content, "\n")) + "\n" + VaultEnd)
+
+	start := bytes.Index(note, []byte(VaultStart))
+	end := bytes.Index(note, []byte(VaultEnd))
+	switch {
+	case start < 0 && end < 0:
+		var out bytes.Buffer
+		out.Write(note)
+		if len(note) > 0 {
+			if !bytes.HasSuffix(note, []byte("\n")) {
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		out.Write(region)
+		out.WriteString("\n")
+		return out.Bytes(), nil
+	case start < 0 || end < start:
+		return nil, fmt.Errorf("%s and %s markers don't match", VaultStart, VaultEnd)
+	case bytes.Contains(note[start+len(VaultStart):], []byte(VaultStart)):
+		return nil, fmt.Errorf("more than one %s marker", VaultStart)
+	case bytes.Contains(note[end+len(VaultEnd):], []byte(VaultEnd)):
+		return nil, fmt.Errorf("more than one %s marker", VaultEnd)
+	}
+
+	var out bytes.Buffer
+	out.Write(note[:start])
+	out.Write(region)
+	out.Write(note[end+len(VaultEnd):])
+	return out.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultPublishKeepsNote(t *testing.T) {
+	vault := &Vault{Dir: t.TempDir(), DateFormat: "2006_01_02"}
+	path := filepath.Join(vault.Dir, "2025_11_03.md")
+
+	// A new note is created with just the region
+	if changed, err := vault.Publish("2025-11-03", []byte("first\n")); err != nil || !changed {
+		t.Fatalf("first publish: changed=%v err=%v", changed, err)
+	}
+
+	// Hand-written text around the region survives later publishes
+	note, _ := os.ReadFile(path)
+	note = append([]byte("# Monday\n\nMorning notes\n\n"), note...)
+	note = append(note, []byte("\nEvening notes\n")...)
+	if err := os.WriteFile(path, note, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := vault.Publish("2025-11-03", []byte("second\n")); err != nil || !changed {
+		t.Fatalf("second publish: changed=%v err=%v", changed, err)
+	}
+	if changed, err := vault.Publish("2025-11-03", []byte("second\n")); err != nil || changed {
+		t.Fatalf("unchanged publish: changed=%v err=%v", changed, err)
+	}
+
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0o644 {
+		t.Errorf("note mode = %v, want 0644", info.Mode())
+	}
+	if leftovers, _ := filepath.Glob(path + ".*"); len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	got, _ := os.ReadFile(path)
+	want := "# Monday\n\nMorning notes\n\n" + VaultStart + "\nsecond\n" + VaultEnd + "\n\nEvening notes\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReplaceRegionMismatchedMarkers(t *testing.T) {
+	for _, note := range []string{
+		"text\n" + VaultStart + "\nno end\n",
+		VaultEnd + "\n" + VaultStart + "\n",
+		VaultStart + "\n" + VaultEnd + "\n" + VaultStart + "\n" + VaultEnd + "\n",
+		VaultStart + "\nold\n" + VaultEnd + "\nstale tail\n" + VaultEnd + "\n",
+	} {
+		if _, err := ReplaceRegion([]byte(note), []byte("x")); err == nil {
+			t.Errorf("expected an error for %q", note)
+		}
+	}
+
+	// Content with a marker would end the region early on the next publish
+	note := []byte(VaultStart + "\nold\n" + VaultEnd + "\n")
+	if _, err := ReplaceRegion(note, []byte("quoting "+VaultEnd+" here")); err == nil {
+		t.Error("expected an error for content containing the end marker")
+	}
+}
@@ -0,0 +1,75 @@
+package social
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/template"
+)
+
+//go:embed social.md
+var defaultTemplate string
+
+// csvHeader names the CSV columns
+var csvHeader = []string{"list", "acct", "display_name", "url", "note", "bot", "last_status_at", "followed_since"}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode social report: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes one row per account, with a list column saying which list it's from
+func WriteCSV(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	lists := []struct {
+		name    string
+		entries []Entry
+	}{
+		{Followers, report.Followers},
+		{Following, report.Following},
+	}
+	for _, list := range lists {
+		for _, e := range list.entries {
+			row := []string{list.name, e.Acct, e.DisplayName, e.URL, e.Note, strconv.FormatBool(e.Bot), e.LastStatusAt, e.FollowedSince}
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown renders the report through the built-in template, or a custom one
+func WriteMarkdown(w io.Writer, report *Report, templatePath string) error {
+	var tmpl *template.Template
+	var err error
+
+	if templatePath == "" {
+		tmpl, err = template.New("social").Parse(defaultTemplate)
+	} else {
+		tmpl, err = template.ParseFiles(templatePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load social template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render social template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package social builds followers and following reports for the social export command
+package social
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+)
+
+// Relationship lists
+const (
+	Followers = "followers"
+	Following = "following"
+)
+
+// Report lists the accounts following and followed by an account
+type Report struct {
+	Account   string  `json:"account"`
+	Date      string  `json:"date"` // When the report was made
+	Followers []Entry `json:"followers,omitempty"`
+	Following []Entry `json:"following,omitempty"`
+}
+
+// Entry is an account in a followers or following list
+type Entry struct {
+	Acct          string `json:"acct"`
+	DisplayName   string `json:"display_name"`
+	URL           string `json:"url"`
+	Note          string `json:"note"` // Converted from HTML
+	Bot           bool   `json:"bot"`
+	LastStatusAt  string `json:"last_status_at"` // Date of the latest post, empty if unknown
+	FollowedSince string `json:"followed_since"` // From the archive, empty without one, or "before <date>" if already there on its first run
+}
+
+// NewEntries converts followers or following accounts into report entries,
+// sorted by acct, with follow dates from the archive's list if there is one
+func NewEntries(accounts []*mastodon.FollowAccount, since map[string]string) []Entry {
+	entries := make([]Entry, 0, len(accounts))
+	for _, account := range accounts {
+		profile := mastodon.ConvertProfile(account.Account)
+		entries = append(entries, Entry{
+			Acct:          profile.Acct,
+			DisplayName:   profile.Name,
+			URL:           profile.URL,
+			Note:          profile.Bio,
+			Bot:           profile.Bot,
+			LastStatusAt:  account.LastStatusAt,
+			FollowedSince: since[profile.Acct],
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Acct) < strings.ToLower(entries[j].Acct)
+	})
+	return entries
+}
+
+// Archive is a local record of when accounts were first seen in each list,
+// since the API doesn't say when a follow happened
+// Dates are only as accurate as how often the archive is updated
+// Each list starts on the first run that records it, and accounts already in
+// it then are kept without a date, since they may have been there for a long time
+type Archive struct {
+	StartedFollowers string            `json:"started_followers,omitempty"` // Date of the first run recording followers
+	StartedFollowing string            `json:"started_following,omitempty"` // Date of the first run recording following
+	Followers        map[string]string `json:"followers"`                   // Acct to date first seen following, empty if there on the first run
+	Following        map[string]string `json:"following"`                   // Acct to date first seen followed, empty if there on the first run
+}
+
+// LoadArchive reads an archive, returning an empty one if the file doesn't exist
+func LoadArchive(path string) (*Archive, error) {
+	archive := &Archive{Followers: map[string]string{}, Following: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return archive, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, archive); err != nil {
+		return nil, fmt.Errorf("failed to parse archive %s: %w", path, err)
+	}
+	if archive.Followers == nil {
+		archive.Followers = map[string]string{}
+	}
+	if archive.Following == nil {
+		archive.Following = map[string]string{}
+	}
+	return archive, nil
+}
+
+// List returns the archive's dates for a relationship list
+func (a *Archive) List(list string) map[string]string {
+	if list == Followers {
+		return a.Followers
+	}
+	return a.Following
+}
+
+// started returns the date of the first run recording a list
+func (a *Archive) started(list string) *string {
+	if list == Followers {
+		return &a.StartedFollowers
+	}
+	return &a.StartedFollowing
+}
+
+// Since returns the follow dates of a list for reports, with accounts that were
+// already there on the list's first run as "before" that date
+func (a *Archive) Since(list string) map[string]string {
+	started := *a.started(list)
+	since := map[string]string{}
+	for acct, date := range a.List(list) {
+		if date == "" && started != "" {
+			date = "before " + started
+		}
+		since[acct] = date
+	}
+	return since
+}
+
+// Record sets date as the first-seen date of accounts new to a list, and
+// forgets accounts no longer in it, so a later re-follow starts over
+// On the list's first run, with no accounts or start date yet, accounts are
+// recorded without a date, and date is kept as when the list started
+func (a *Archive) Record(list string, accounts []*mastodon.FollowAccount, date string) {
+	dates := a.List(list)
+	if started := a.started(list); *started == "" && len(dates) == 0 {
+		*started = date
+		date = ""
+	}
+	current := map[string]bool{}
+	for _, account := range accounts {
+		acct := account.Account.Acct
+		current[acct] = true
+		if _, ok := dates[acct]; !ok {
+			dates[acct] = date
+		}
+	}
+	for acct := range dates {
+		if !current[acct] {
+			delete(dates, acct)
+		}
+	}
+}
+
+// Save writes the archive as indented JSON
+func (a *Archive) Save(path string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package social
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+	mastodonAPI "github.com/mattn/go-mastodon"
+)
+
+func accounts(accts ...string) []*mastodon.FollowAccount {
+	list := make([]*mastodon.FollowAccount, 0, len(accts))
+	for _, acct := range accts {
+		list = append(list, &mastodon.FollowAccount{Account: &mastodonAPI.Account{Acct: acct}})
+	}
+	return list
+}
+
+func TestArchiveFirstRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.json")
+
+	archive, err := LoadArchive(path)
+	if err != nil {
+		t.Fatalf("LoadArchive failed: %v", err)
+	}
+	archive.Record(Followers, accounts("bob@b.example", "carol@c.example"), "2025-11-10")
+	archive.Record(Following, accounts("bob@b.example"), "2025-11-10")
+	if err := archive.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	since := archive.Since(Followers)
+	if since["bob@b.example"] != "before 2025-11-10" || since["carol@c.example"] != "before 2025-11-10" {
+		t.Errorf("first run followers = %v, want both before the first run", since)
+	}
+	if got := archive.Since(Following)["bob@b.example"]; got != "before 2025-11-10" {
+		t.Errorf("first run following = %q, want before the first run", got)
+	}
+
+	// Only accounts that show up later get a date
+	archive, err = LoadArchive(path)
+	if err != nil {
+		t.Fatalf("LoadArchive failed: %v", err)
+	}
+	archive.Record(Followers, accounts("bob@b.example", "dave@d.example"), "2025-11-17")
+
+	since = archive.Since(Followers)
+	want := map[string]string{"bob@b.example": "before 2025-11-10", "dave@d.example": "2025-11-17"}
+	if len(since) != len(want) {
+		t.Errorf("second run followers = %v, want %v", since, want)
+	}
+	for acct, date := range want {
+		if since[acct] != date {
+			t.Errorf("second run %s = %q, want %q", acct, since[acct], date)
+		}
+	}
+	if archive.StartedFollowers != "2025-11-10" {
+		t.Errorf("StartedFollowers = %q, want the first run", archive.StartedFollowers)
+	}
+}
+
+func TestArchiveListsStartSeparately(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.json")
+
+	// Followers first, then following on a later run
+	archive, err := LoadArchive(path)
+	if err != nil {
+		t.Fatalf("LoadArchive failed: %v", err)
+	}
+	archive.Record(Followers, accounts("bob@b.example"), "2025-11-10")
+	if err := archive.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	archive, err = LoadArchive(path)
+	if err != nil {
+		t.Fatalf("LoadArchive failed: %v", err)
+	}
+	archive.Record(Following, accounts("carol@c.example"), "2025-11-17")
+	archive.Record(Followers, accounts("bob@b.example", "dave@d.example"), "2025-11-17")
+
+	if got := archive.Since(Following)["carol@c.example"]; got != "before 2025-11-17" {
+		t.Errorf("following on its first run = %q, want before that run", got)
+	}
+	followers := archive.Since(Followers)
+	if followers["bob@b.example"] != "before 2025-11-10" || followers["dave@d.example"] != "2025-11-17" {
+		t.Errorf("followers = %v, want bob from the first run and dave new", followers)
+	}
+}
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"text/template"
+)
+
+//go:embed stats.md
+var defaultTemplate string
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	return nil
+}
+
+// WriteMarkdown renders the report through a template
+// If templatePath is empty, uses the embedded default stats template
+func WriteMarkdown(w io.Writer, report *Report, templatePath string) error {
+	var tmpl *template.Template
+	var err error
+
+	if templatePath == "" {
+		tmpl, err = template.New("stats").Parse(defaultTemplate)
+	} else {
+		tmpl, err = template.ParseFiles(templatePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load stats template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render stats template: %w", err)
+	}
+	return nil
+}
+
+// WriteTable writes the report as aligned plain-text tables for the terminal
+func WriteTable(w io.Writer, report *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Activity from %s to %s\n\n", report.StartDate, report.EndDate)
+
+	fmt.Fprintf(tw, "Posts\t%d\n", report.Counts.Posts)
+	fmt.Fprintf(tw, "Replies\t%d\n", report.Counts.Replies)
+	fmt.Fprintf(tw, "Boosts\t%d\n", report.Counts.Boosts)
+	fmt.Fprintf(tw, "Favorites\t%d\n", report.Counts.Favorites)
+	fmt.Fprintf(tw, "Total\t%d\n\n", report.Counts.Total)
+
+	fmt.Fprintf(tw, "Received\tReplies\tBoosts\tFavourites\n")
+	fmt.Fprintf(tw, "\t%d\t%d\t%d\n\n", report.Received.Replies, report.Received.Boosts, report.Received.Favourites)
+
+	writeTopPosts(tw, "Most favourited", report.TopFavourited)
+	writeTopPosts(tw, "Most boosted", report.TopReblogged)
+
+	writeCounts(tw, "Top hashtags", "#", report.Hashtags)
+	writeCounts(tw, "Most boosted accounts", "@", report.BoostedAccounts)
+	writeCounts(tw, "Most favorited accounts", "@", report.FavoritedAccounts)
+
+	fmt.Fprintf(tw, "Posting hours\n")
+	fmt.Fprintf(tw, "     0         1         2\n")
+	fmt.Fprintf(tw, "     012345678901234567890123\n")
+	for _, row := range report.Heatmap {
+		fmt.Fprintf(tw, "%s  %s\n", row.Weekday, row.Shade)
+	}
+
+	return tw.Flush()
+}
+
+// writeTopPosts writes a ranked post table, skipping it when empty
+func writeTopPosts(w io.Writer, title string, posts []TopPost) {
+	if len(posts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\tFavs\tBoosts\tReplies\tDate\tPost\n", title)
+	for _, post := range posts {
+		fmt.Fprintf(w, "\t%d\t%d\t%d\t%s\t%s\n", post.FavouritesCount, post.ReblogsCount, post.RepliesCount, post.Date, post.Excerpt)
+	}
+	fmt.Fprintln(w)
+}
+
+// writeCounts writes a name/count table, skipping it when empty
+func writeCounts(w io.Writer, title, prefix string, counts []Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\tCount\n", title)
+	for _, count := range counts {
+		fmt.Fprintf(w, "  %s%s\t%d\n", prefix, count.Name, count.Count)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// heatmapShades are the characters used to shade heatmap cells, from none to most
+const heatmapShades = " .:-=+*#%@"
+
+// Report summarizes activity and engagement for a time range
+type Report struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+
+	Counts   Counts   `json:"counts"`
+	Received Received `json:"received"`
+
+	TopFavourited []TopPost `json:"top_favourited"`
+	TopReblogged  []TopPost `json:"top_reblogged"`
+
+	Heatmap []HeatmapRow `json:"heatmap"` // Posting activity by weekday and hour
+
+	Hashtags          []Count `json:"hashtags"`           // Hashtags used in my own posts
+	BoostedAccounts   []Count `json:"boosted_accounts"`   // Authors I boosted most
+	FavoritedAccounts []Count `json:"favorited_accounts"` // Authors I favorited most
+}
+
+// Counts holds post counts by type
+type Counts struct {
+	Posts     int `json:"posts"`   // Original posts that aren't replies
+	Replies   int `json:"replies"` // Replies to other posts
+	Boosts    int `json:"boosts"`
+	Favorites int `json:"favorites"`
+	Total     int `json:"total"`
+}
+
+// Received holds the engagement received on my own posts and replies
+type Received struct {
+	Replies    int64 `json:"replies"`
+	Boosts     int64 `json:"boosts"`
+	Favourites int64 `json:"favourites"`
+}
+
+// TopPost is a post ranked by engagement
+type TopPost struct {
+	Date            string `json:"date"`
+	URL             string `json:"url"`
+	Excerpt         string `json:"excerpt"`
+	FavouritesCount int64  `json:"favourites_count"`
+	ReblogsCount    int64  `json:"reblogs_count"`
+	RepliesCount    int64  `json:"replies_count"`
+}
+
+// HeatmapRow holds posting counts for one weekday, by hour of day
+type HeatmapRow struct {
+	Weekday string  `json:"weekday"`
+	Hours   [24]int `json:"hours"`
+	Shade   string  `json:"-"` // One character per hour, darker for more posts
+}
+
+// Count is a name with the number of times it occurred
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Compute builds a report from fetched posts
+// topN limits the length of the top posts, hashtags, and accounts lists; below zero counts as zero
+func Compute(startDate, endDate string, posts []templates.Post, topN int) *Report {
+	topN = max(topN, 0)
+	report := &Report{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	// Counts and received engagement share the aggregation exposed to templates
+	summary := templates.ComputeStats(posts)
+	report.Counts = Counts{
+		Posts:     summary.OwnPosts - summary.Replies,
+		Replies:   summary.Replies,
+		Boosts:    summary.Boosts,
+		Favorites: summary.Favorites,
+		Total:     summary.Total,
+	}
+	report.Received = Received{
+		Replies:    summary.RepliesReceived,
+		Boosts:     summary.ReblogsReceived,
+		Favourites: summary.FavouritesReceived,
+	}
+
+	var own []templates.Post
+	var hours [7][24]int
+	hashtags := map[string]int{}
+	boosted := map[string]int{}
+	favorited := map[string]int{}
+
+	for _, post := range posts {
+		switch {
+		case post.IsFavorited:
+			if post.OriginalPost != nil {
+				favorited[post.OriginalPost.Author()]++
+			}
+			// Favorites carry the original post's timestamp rather than when
+			// they were favorited, so they're left out of the heatmap
+			continue
+		case post.IsBoost:
+			if post.OriginalPost != nil {
+				boosted[post.OriginalPost.Author()]++
+			}
+		default:
+			own = append(own, post)
+			for _, tag := range post.Tags {
+				hashtags[tag]++
+			}
+		}
+
+		hours[mondayFirst(post.CreatedAt.Weekday())][post.CreatedAt.Hour()]++
+	}
+
+	report.TopFavourited = topPosts(own, topN, func(p templates.Post) int64 { return p.FavouritesCount })
+	report.TopReblogged = topPosts(own, topN, func(p templates.Post) int64 { return p.ReblogsCount })
+	report.Heatmap = heatmap(hours)
+	report.Hashtags = topCounts(hashtags, topN)
+	report.BoostedAccounts = topCounts(boosted, topN)
+	report.FavoritedAccounts = topCounts(favorited, topN)
+
+	return report
+}
+
+// mondayFirst maps a weekday to an index where Monday is 0 and Sunday is 6
+func mondayFirst(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// topPosts returns up to n posts with the highest non-zero score, highest first
+func topPosts(posts []templates.Post, n int, score func(templates.Post) int64) []TopPost {
+	ranked := make([]templates.Post, 0, len(posts))
+	for _, post := range posts {
+		if score(post) > 0 {
+			ranked = append(ranked, post)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return score(ranked[i]) > score(ranked[j])
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+
+	result := make([]TopPost, 0, len(ranked))
+	for _, post := range ranked {
+		result = append(result, TopPost{
+			Date:            post.FormattedDate,
+			URL:             post.URL,
+			Excerpt:         excerpt(post.Content, 60),
+			FavouritesCount: post.FavouritesCount,
+			ReblogsCount:    post.ReblogsCount,
+			RepliesCount:    post.RepliesCount,
+		})
+	}
+	return result
+}
+
+// topCounts returns up to n entries with the highest counts, ties broken by name
+func topCounts(counts map[string]int, n int) []Count {
+	result := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, Count{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// heatmap converts weekday/hour counts into rows shaded relative to the busiest hour
+func heatmap(hours [7][24]int) []HeatmapRow {
+	busiest := 0
+	for _, day := range hours {
+		for _, count := range day {
+			if count > busiest {
+				busiest = count
+			}
+		}
+	}
+
+	rows := make([]HeatmapRow, 0, 7)
+	for i, day := range hours {
+		row := HeatmapRow{
+			Weekday: time.Weekday((i + 1) % 7).String()[:3],
+			Hours:   day,
+		}
+		var shade strings.Builder
+		for _, count := range day {
+			level := 0
+			if busiest > 0 {
+				level = count * (len(heatmapShades) - 1) / busiest
+			}
+			if count > 0 && level == 0 {
+				level = 1
+			}
+			shade.WriteByte(heatmapShades[level])
+		}
+		row.Shade = shade.String()
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// excerpt returns the first line of content, truncated to at most n runes
+func excerpt(content string, n int) string {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(content), "\n", 2)[0])
+	runes := []rune(line)
+	if len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return line
+}
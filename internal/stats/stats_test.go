@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// testPosts are two posts of my own, a reply, a boost, and a favorite
+func testPosts() []templates.Post {
+	monday := time.Date(2025, 11, 3, 9, 30, 0, 0, time.UTC)
+	return []templates.Post{
+		{ID: "1", CreatedAt: monday, FormattedDate: "2025-11-03", Content: "First", Tags: []string{"go", "templates"}, FavouritesCount: 3, ReblogsCount: 1},
+		{ID: "2", CreatedAt: monday.Add(time.Hour), FormattedDate: "2025-11-03", Content: "Second", Tags: []string{"go"}, FavouritesCount: 5},
+		{ID: "3", CreatedAt: monday.Add(24 * time.Hour), FormattedDate: "2025-11-04", Content: "A reply", IsReply: true, RepliesCount: 2},
+		{ID: "4", CreatedAt: monday, IsBoost: true, OriginalPost: &templates.OriginalPost{AuthorAcct: "bob@other.example"}},
+		{ID: "5", CreatedAt: monday, IsFavorited: true, OriginalPost: &templates.OriginalPost{AuthorAcct: "carol@third.example"}},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	report := Compute("2025-11-03", "2025-11-10", testPosts(), 1)
+
+	want := Counts{Posts: 2, Replies: 1, Boosts: 1, Favorites: 1, Total: 5}
+	if report.Counts != want {
+		t.Errorf("counts: got %+v, want %+v", report.Counts, want)
+	}
+	if report.Received != (Received{Replies: 2, Boosts: 1, Favourites: 8}) {
+		t.Errorf("received: got %+v", report.Received)
+	}
+
+	// Lists are cut to topN, highest first
+	if len(report.TopFavourited) != 1 || report.TopFavourited[0].Excerpt != "Second" {
+		t.Errorf("top favourited: got %+v", report.TopFavourited)
+	}
+	if len(report.Hashtags) != 1 || report.Hashtags[0] != (Count{Name: "go", Count: 2}) {
+		t.Errorf("hashtags: got %+v", report.Hashtags)
+	}
+	if len(report.BoostedAccounts) != 1 || report.BoostedAccounts[0].Name != "bob@other.example" {
+		t.Errorf("boosted accounts: got %+v", report.BoostedAccounts)
+	}
+
+	// Favorites are left out of the heatmap; Monday 09:00 has a post and a boost
+	if got := report.Heatmap[0].Hours[9]; got != 2 {
+		t.Errorf("Monday 09:00: got %d posts, want 2", got)
+	}
+}
+
+func TestComputeNegativeTop(t *testing.T) {
+	report := Compute("2025-11-03", "2025-11-10", testPosts(), -1)
+	if len(report.TopFavourited) != 0 || len(report.Hashtags) != 0 || len(report.FavoritedAccounts) != 0 {
+		t.Errorf("expected empty top lists, got %+v", report)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := excerpt("  First line\nSecond line", 60); got != "First line" {
+		t.Errorf("got %q", got)
+	}
+	if got := excerpt(strings.Repeat("é", 10), 5); got != "éééé…" {
+		t.Errorf("got %q", got)
+	}
+}
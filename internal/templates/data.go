@@ -0,0 +1,33 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteData writes template data as JSON, for caching and previewing with serve
+func WriteData(w io.Writer, data *TemplateData) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode template data: %w", err)
+	}
+	return nil
+}
+
+// ReadData reads template data written by WriteData
+func ReadData(r io.Reader) (*TemplateData, error) {
+	var data TemplateData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode template data: %w", err)
+	}
+
+	// The profile's formatted date isn't written, so derive it again
+	if data.Profile != nil {
+		data.Profile.FormattedCreatedAt = data.Profile.CreatedAt.Format("2006-01-02")
+	}
+
+	return &data, nil
+}
@@ -0,0 +1,157 @@
+package templates
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// zeroWidthSpace stops a line from being read as markup without changing how it looks
+const zeroWidthSpace = "\u200b"
+
+// funcMap holds the functions available to every template, mainly escaping
+// for formats other than Markdown
+var funcMap = template.FuncMap{
+	"orgText":  orgText,
+	"orgLine":  orgLine,
+	"orgLink":  orgLink,
+	"gemText":  gemText,
+	"gemLine":  gemLine,
+	"gemLink":  gemLink,
+	"gemQuote": gemQuote,
+	"quote":    quote,
+	"wikilink": wikilink,
+	"wikify":   wikify,
+	"cell":     cell,
+}
+
+// cell flattens text onto one line for a Markdown table cell, escaping pipes
+// that would end the cell early
+func cell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", `\|`)
+}
+
+// orgText escapes multi-line text for Org-mode, so lines that would start a
+// heading, keyword, block, comment, drawer, or table stay plain text
+func orgText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, "*") || strings.HasPrefix(trimmed, "#") ||
+			strings.HasPrefix(trimmed, ":") || strings.HasPrefix(trimmed, "|") {
+			lines[i] = zeroWidthSpace + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// orgLine flattens text onto one line, for headings and property values, and
+// keeps a trailing :word: (such as a custom emoji) from being read as heading tags
+func orgLine(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if strings.HasSuffix(s, ":") {
+		s += zeroWidthSpace
+	}
+	return s
+}
+
+// orgLink returns an Org-mode link, with brackets that would end it early escaped
+func orgLink(url, description string) string {
+	url = strings.NewReplacer("[", "%5B", "]", "%5D").Replace(url)
+	description = strings.NewReplacer("[", "(", "]", ")").Replace(orgLine(description))
+	if description == "" {
+		return "[[" + url + "]]"
+	}
+	return "[[" + url + "][" + description + "]]"
+}
+
+// gemText escapes multi-line text for Gemtext, so lines that would be read as
+// links, headings, list items, quotes, or preformatting toggles stay plain text
+func gemText(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		for _, prefix := range []string{"=>", "#", "* ", ">", "```"} {
+			if strings.HasPrefix(line, prefix) {
+				lines[i] = zeroWidthSpace + line
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gemLine flattens text onto one line, for headings and link labels
+func gemLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// gemLink returns a Gemtext link line, the only way to link in Gemini
+// Labels that only repeat the URL are left out, since clients show the URL then
+func gemLink(url, label string) string {
+	url = strings.ReplaceAll(url, " ", "%20")
+	if label = gemLine(label); label != "" && label != url {
+		return "=> " + url + " " + label
+	}
+	return "=> " + url
+}
+
+// gemQuote quotes multi-line text for Gemtext, one quote line per line
+func gemQuote(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quote quotes multi-line text as a Markdown blockquote, which looks the same
+// as a Gemtext one
+func quote(s string) string {
+	return gemQuote(s)
+}
+
+// wikilink returns a [[wikilink]] to the note with a name, as Obsidian and
+// Logseq link notes, dropping characters that aren't allowed in note links
+func wikilink(name string) string {
+	name = strings.NewReplacer("[", "", "]", "", "|", "", "#", "", "^", "").Replace(gemLine(name))
+	return "[[" + name + "]]"
+}
+
+// hashtagPattern and mentionPattern find hashtags and mentions in post content,
+// but not inside words, paths, or URL fragments
+// Hashtags can be in any script, so letters and digits are matched as Unicode
+var (
+	hashtagPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_/&#])#([\p{L}\p{N}_]+)`)
+	mentionPattern = regexp.MustCompile(`(^|[^\p{L}\p{N}_/@])@(\w+(?:@[\w.-]*\w)?)`)
+)
+
+// wikify turns the hashtags and mentions in post content into wikilinks, to a
+// note per hashtag and a note per person named "@" and their full account name
+// Only the post's own tags and mentions are linked, so stray text like "#1" isn't
+func wikify(content string, tags []string, mentions []Mention) string {
+	tagSet := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tagSet[strings.ToLower(tag)] = true
+	}
+	content = hashtagPattern.ReplaceAllStringFunc(content, func(match string) string {
+		sub := hashtagPattern.FindStringSubmatch(match)
+		if !tagSet[strings.ToLower(sub[2])] {
+			return match
+		}
+		return sub[1] + wikilink(sub[2])
+	})
+
+	return mentionPattern.ReplaceAllStringFunc(content, func(match string) string {
+		sub := mentionPattern.FindStringSubmatch(match)
+		for _, mention := range mentions {
+			if strings.EqualFold(sub[2], mention.Username) || strings.EqualFold(sub[2], mention.Acct) {
+				return sub[1] + wikilink("@"+mention.Acct)
+			}
+		}
+		return match
+	})
+}
@@ -0,0 +1,58 @@
+package templates
+
+import "testing"
+
+func TestEscapeFuncs(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"orgText heading", orgText("* not a heading\nplain"), "\u200b* not a heading\nplain"},
+		{"orgText keyword", orgText("#+end_quote\n  | not | a table |"), "\u200b#+end_quote\n\u200b  | not | a table |"},
+		{"orgText drawer", orgText(":END:"), "\u200b:END:"},
+		{"orgLine", orgLine("multi\nline  text"), "multi line text"},
+		{"orgLine tags", orgLine("Bob :blobcat:"), "Bob :blobcat:\u200b"},
+		{"orgLink", orgLink("https://example.com/[1]", "see [1]"), "[[https://example.com/%5B1%5D][see (1)]]"},
+		{"orgLink bare", orgLink("https://example.com", ""), "[[https://example.com]]"},
+		{"gemText", gemText("=> not a link\n# not a heading\n* not a list\n*emphasis*\n```"), "\u200b=> not a link\n\u200b# not a heading\n\u200b* not a list\n*emphasis*\n\u200b```"},
+		{"gemLink", gemLink("https://example.com/a b", "A\nlink"), "=> https://example.com/a%20b A link"},
+		{"gemLink same label", gemLink("https://example.com", "https://example.com"), "=> https://example.com"},
+		{"gemQuote", gemQuote("one\n\ntwo"), "> one\n>\n> two"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestWikify(t *testing.T) {
+	mentions := []Mention{{Username: "bob", Acct: "bob@other.example"}}
+	tests := []struct {
+		in, want string
+	}{
+		{"Notes on #GoLang templates", "Notes on [[GoLang]] templates"},
+		{"@bob spaces, obviously.", "[[@bob@other.example]] spaces, obviously."},
+		{"cc @bob@other.example.", "cc [[@bob@other.example]]."},
+		{"Item #1 for @carol", "Item #1 for @carol"},
+		{"https://example.com/page#golang and mail@bob", "https://example.com/page#golang and mail@bob"},
+		{"Lunch at the #Café, #日本語 practice", "Lunch at the [[Café]], [[日本語]] practice"},
+		{"naïve#café and café@bob", "naïve#café and café@bob"},
+	}
+	for _, tt := range tests {
+		if got := wikify(tt.in, []string{"golang", "café", "日本語"}, mentions); got != tt.want {
+			t.Errorf("wikify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if got := wikilink("a [weird] | name#1"); got != "[[a weird  name1]]" {
+		t.Errorf("wikilink = %q", got)
+	}
+}
+
+func TestCell(t *testing.T) {
+	if got, want := cell("Tabs | spaces\nor  both"), `Tabs \| spaces or both`; got != want {
+		t.Errorf("cell = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package templates
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// blankLines separates paragraphs in post content
+var blankLines = regexp.MustCompile(`\n[ \t]*\n`)
+
+// EmojiImage returns the Markdown image a custom emoji is rendered as in the
+// "image" emoji mode
+func EmojiImage(emoji Emoji) string {
+	return "![:" + emoji.Shortcode + ":](" + emoji.URL + ")"
+}
+
+// TextHTML converts post content, which is plain text, to escaped HTML with a
+// paragraph per blank-line-separated block and <br /> for single newlines
+// The visible text of links becomes links again, and emoji images become <img>
+// tags; the <br /> form is also valid XHTML
+func TextHTML(text string, links []Link, emojis []Emoji) string {
+	linker := linkReplacer(links, emojis)
+
+	var b strings.Builder
+	for _, para := range blankLines.Split(strings.TrimSpace(text), -1) {
+		if para = strings.TrimSpace(para); para == "" {
+			continue
+		}
+		lines := strings.Split(para, "\n")
+		for i, line := range lines {
+			lines[i] = linker.Replace(html.EscapeString(strings.TrimRight(line, " \t")))
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br />\n") + "</p>\n")
+	}
+	return b.String()
+}
+
+// linkReplacer turns the escaped visible text of links into anchors, trying
+// longer text first so a link isn't split by one whose text it contains, and
+// escaped emoji images into <img> tags
+func linkReplacer(links []Link, emojis []Emoji) *strings.Replacer {
+	sorted := append([]Link{}, links...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Text) > len(sorted[j].Text)
+	})
+
+	var pairs []string
+	for _, emoji := range emojis {
+		if emoji.URL == "" {
+			continue
+		}
+		img := `<img src="` + html.EscapeString(emoji.URL) + `" alt=":` + html.EscapeString(emoji.Shortcode) + `:" class="emoji" />`
+		pairs = append(pairs, html.EscapeString(EmojiImage(emoji)), img)
+	}
+	for _, link := range sorted {
+		if link.Text == "" || link.Href == "" {
+			continue
+		}
+		text := html.EscapeString(link.Text)
+		pairs = append(pairs, text, `<a href="`+html.EscapeString(link.Href)+`">`+text+`</a>`)
+	}
+	return strings.NewReplacer(pairs...)
+}
@@ -0,0 +1,31 @@
+package templates
+
+import "testing"
+
+func TestTextHTML(t *testing.T) {
+	links := []Link{
+		{Href: "https://example.com/a?b=1&c=2", Text: "example.com/a?b=1&c=2"},
+		{Href: "https://example.com/", Text: "example.com"},
+	}
+	emojis := []Emoji{{Shortcode: "blobcat", URL: "https://example.com/blobcat.png?s=1&t=2"}}
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Hello *world* & <friends>", "<p>Hello *world* &amp; &lt;friends&gt;</p>\n"},
+		{"# not a heading\n- not a list\n\n\n1. nor this", "<p># not a heading<br />\n- not a list</p>\n<p>1. nor this</p>\n"},
+		{
+			"See example.com/a?b=1&c=2 and example.com",
+			`<p>See <a href="https://example.com/a?b=1&amp;c=2">example.com/a?b=1&amp;c=2</a> and <a href="https://example.com/">example.com</a></p>` + "\n",
+		},
+		{
+			"Hi ![:blobcat:](https://example.com/blobcat.png?s=1&t=2) :blobcat:",
+			`<p>Hi <img src="https://example.com/blobcat.png?s=1&amp;t=2" alt=":blobcat:" class="emoji" /> :blobcat:</p>` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := TextHTML(tt.in, links, emojis); got != tt.want {
+			t.Errorf("TextHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package templates
+
+import "sort"
+
+// ComputeStats aggregates counts and engagement across posts
+func ComputeStats(posts []Post) Stats {
+	var stats Stats
+	var bestEngagement int64
+	perDay := make(map[string]int)
+	var dates []string
+	boosted := make(map[string]bool)
+	favorited := make(map[string]bool)
+
+	for i, post := range posts {
+		stats.Total++
+
+		if _, exists := perDay[post.FormattedDate]; !exists {
+			dates = append(dates, post.FormattedDate)
+		}
+		perDay[post.FormattedDate]++
+
+		switch {
+		case post.IsFavorited:
+			stats.Favorites++
+			if post.OriginalPost != nil {
+				favorited[post.OriginalPost.Author()] = true
+			}
+		case post.IsBoost:
+			stats.Boosts++
+			if post.OriginalPost != nil {
+				boosted[post.OriginalPost.Author()] = true
+			}
+		default:
+			stats.OwnPosts++
+			if post.IsReply {
+				stats.Replies++
+			}
+			stats.RepliesReceived += post.RepliesCount
+			stats.ReblogsReceived += post.ReblogsCount
+			stats.FavouritesReceived += post.FavouritesCount
+
+			engagement := post.RepliesCount + post.ReblogsCount + post.FavouritesCount
+			if engagement > bestEngagement {
+				bestEngagement = engagement
+				stats.MostEngaged = &posts[i]
+			}
+		}
+	}
+
+	stats.TotalEngagement = stats.RepliesReceived + stats.ReblogsReceived + stats.FavouritesReceived
+
+	for _, date := range dates {
+		stats.PerDay = append(stats.PerDay, DayCount{Date: date, Count: perDay[date]})
+	}
+	stats.BoostedAuthors = sortedKeys(boosted)
+	stats.FavoritedAuthors = sortedKeys(favorited)
+
+	return stats
+}
+
+// Author returns the most specific available name for the original post's author
+func (o *OriginalPost) Author() string {
+	if o.AuthorAcct != "" {
+		return o.AuthorAcct
+	}
+	return o.AuthorUsername
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
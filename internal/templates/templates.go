@@ -4,13 +4,42 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 )
 
 //go:embed default.md
 var defaultTemplate string
 
+//go:embed link-roundup.md
+var linkRoundupTemplate string
+
+//go:embed about.md
+var aboutTemplate string
+
+//go:embed org.org
+var orgTemplate string
+
+//go:embed gemtext.gmi
+var gemtextTemplate string
+
+//go:embed daily-note.md
+var dailyNoteTemplate string
+
+// builtinTemplates maps built-in template names to their embedded content
+var builtinTemplates = map[string]string{
+	"default":      defaultTemplate,
+	"link-roundup": linkRoundupTemplate,
+	"about":        aboutTemplate,
+	"org":          orgTemplate,
+	"gemtext":      gemtextTemplate,
+	"daily-note":   dailyNoteTemplate,
+}
+
 // GetDefaultTemplate returns the embedded default template content
 func GetDefaultTemplate() (string, error) {
 	return defaultTemplate, nil
@@ -19,6 +48,7 @@ func GetDefaultTemplate() (string, error) {
 // GroupPostsByDay organizes posts by date and type (own, boosted, favorited)
 func GroupPostsByDay(posts []Post) []DayGroup {
 	dayMap := make(map[string]*DayGroup)
+	dayPosts := make(map[string][]Post)
 	var dates []string
 
 	for _, post := range posts {
@@ -29,6 +59,7 @@ func GroupPostsByDay(posts []Post) []DayGroup {
 			dayMap[date] = &DayGroup{Date: date}
 			dates = append(dates, date)
 		}
+		dayPosts[date] = append(dayPosts[date], post)
 
 		// Add post to appropriate category
 		if post.IsFavorited {
@@ -43,12 +74,72 @@ func GroupPostsByDay(posts []Post) []DayGroup {
 	// Convert map to sorted slice
 	result := make([]DayGroup, 0, len(dates))
 	for _, date := range dates {
+		dayMap[date].Stats = ComputeStats(dayPosts[date])
 		result = append(result, *dayMap[date])
 	}
 
 	return result
 }
 
+// GroupLinksByDomain collects the external links from all posts, including
+// boosted and favorited originals, grouped by domain and sorted by domain name
+// Each URL is listed once, with the first post it appeared in
+// Links without a domain, such as from hand-made data, get one from their URL
+func GroupLinksByDomain(posts []Post) []DomainGroup {
+	domainMap := make(map[string]*DomainGroup)
+	seen := make(map[string]bool)
+
+	for _, post := range posts {
+		links := append([]Link{}, post.Links...)
+		postURL := post.URL
+		if post.OriginalPost != nil {
+			links = append(links, post.OriginalPost.Links...)
+			postURL = post.OriginalPost.URL
+		}
+
+		for _, link := range links {
+			if seen[link.Href] {
+				continue
+			}
+			seen[link.Href] = true
+
+			if link.Domain = linkDomain(link); link.Domain == "" {
+				continue
+			}
+			if _, exists := domainMap[link.Domain]; !exists {
+				domainMap[link.Domain] = &DomainGroup{Domain: link.Domain}
+			}
+			domainMap[link.Domain].Links = append(domainMap[link.Domain].Links, LinkRef{
+				Link:    link,
+				PostURL: postURL,
+				Date:    post.FormattedDate,
+			})
+		}
+	}
+
+	result := make([]DomainGroup, 0, len(domainMap))
+	for _, group := range domainMap {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Domain < result[j].Domain
+	})
+
+	return result
+}
+
+// linkDomain returns a link's domain without a leading "www.", taken from its
+// URL if it has none, or an empty string if the URL has no host
+func linkDomain(link Link) string {
+	domain := link.Domain
+	if domain == "" {
+		if u, err := url.Parse(link.Href); err == nil {
+			domain = u.Hostname()
+		}
+	}
+	return strings.TrimPrefix(strings.ToLower(domain), "www.")
+}
+
 // Renderer handles loading and rendering markdown templates
 type Renderer struct {
 	tmpl *template.Template
@@ -56,20 +147,25 @@ type Renderer struct {
 
 // NewRenderer creates a new template renderer
 // If templatePath is empty, uses the embedded default template
+// If templatePath names a built-in template (e.g. "link-roundup"), uses that
 // Otherwise loads the template from the specified file
 func NewRenderer(templatePath string) (*Renderer, error) {
 	var tmpl *template.Template
 	var err error
 
 	if templatePath == "" {
-		// Use embedded default template
-		tmpl, err = template.New("default").Parse(defaultTemplate)
+		templatePath = "default"
+	}
+
+	if builtin, ok := builtinTemplates[templatePath]; ok {
+		// Use embedded built-in template
+		tmpl, err = template.New(templatePath).Funcs(funcMap).Parse(builtin)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse default template: %w", err)
+			return nil, fmt.Errorf("failed to parse %s template: %w", templatePath, err)
 		}
 	} else {
 		// Load template from file
-		tmpl, err = template.ParseFiles(templatePath)
+		tmpl, err = template.New(filepath.Base(templatePath)).Funcs(funcMap).ParseFiles(templatePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load template from %s: %w", templatePath, err)
 		}
@@ -105,3 +201,18 @@ func (r *Renderer) RenderToFile(filename string, data *TemplateData) error {
 
 	return nil
 }
+
+// RenderString executes a short inline template, such as a commit message or
+// file name, with the given data, usually *TemplateData or a Post
+func RenderString(text string, data any) (string, error) {
+	tmpl, err := template.New("inline").Funcs(funcMap).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %w", text, err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", text, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,119 @@
+package templates
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTemplateMarkersUnique(t *testing.T) {
+	// A post of my own that I also favorited appears in two sections
+	own := Post{ID: "1", FormattedDate: "2025-11-03", Content: "Mine"}
+	fav := own
+	fav.IsFavorited = true
+	fav.OriginalPost = &OriginalPost{Content: "Mine"}
+
+	posts := []Post{own, fav}
+	data := &TemplateData{Posts: posts, Days: GroupPostsByDay(posts), Stats: ComputeStats(posts), Markers: true}
+
+	renderer, err := NewRenderer("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, m := range regexp.MustCompile(`<!-- status:(\S+) -->`).FindAllStringSubmatch(buf.String(), -1) {
+		if seen[m[1]] {
+			t.Errorf("marker %s appears twice:\n%s", m[1], buf.String())
+		}
+		seen[m[1]] = true
+	}
+	for _, id := range []string{"summary", "1", "fav-1"} {
+		if !seen[id] {
+			t.Errorf("missing marker %s:\n%s", id, buf.String())
+		}
+	}
+}
+
+func TestGroupLinksByDomain(t *testing.T) {
+	posts := []Post{
+		{
+			URL: "https://example.social/@me/1", FormattedDate: "2025-11-03",
+			Links: []Link{
+				{Href: "https://www.blog.example/a", Text: "blog.example/a", Domain: "blog.example"},
+				{Href: "https://news.example/story", Text: "news.example/story"}, // No domain
+			},
+		},
+		{
+			URL: "https://example.social/@me/2", FormattedDate: "2025-11-04",
+			Links: []Link{
+				{Href: "https://www.blog.example/a", Text: "again", Domain: "blog.example"}, // Seen already
+				{Href: "https://WWW.Blog.example/b", Text: "b", Domain: "WWW.Blog.example"},
+				{Href: "mailto:me@example.com", Text: "mail"}, // No host
+			},
+		},
+		{
+			URL: "https://example.social/@me/3", FormattedDate: "2025-11-05", IsBoost: true,
+			OriginalPost: &OriginalPost{
+				URL:   "https://other.example/@bob/9",
+				Links: []Link{{Href: "https://news.example/other", Text: "other", Domain: "news.example"}},
+			},
+		},
+	}
+
+	groups := GroupLinksByDomain(posts)
+	var got []string
+	for _, group := range groups {
+		for _, link := range group.Links {
+			got = append(got, group.Domain+" "+link.Href+" "+link.PostURL+" "+link.Date)
+		}
+	}
+	want := []string{
+		"blog.example https://www.blog.example/a https://example.social/@me/1 2025-11-03",
+		"blog.example https://WWW.Blog.example/b https://example.social/@me/2 2025-11-04",
+		"news.example https://news.example/story https://example.social/@me/1 2025-11-03",
+		"news.example https://news.example/other https://other.example/@bob/9 2025-11-05",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSummaryEngagementWording(t *testing.T) {
+	tests := []struct {
+		name   string
+		author *Author
+		want   string
+	}{
+		{name: "my account", want: "My posts received 3 favourites"},
+		{name: "timeline digest", author: &Author{Name: "Alice", Acct: "alice@example.social"}, want: " Posts received 3 favourites"},
+	}
+	for _, tt := range tests {
+		posts := []Post{{ID: "1", FormattedDate: "2025-11-03", Content: "Hello", FavouritesCount: 3, Author: tt.author}}
+		data := &TemplateData{Posts: posts, Days: GroupPostsByDay(posts), Stats: ComputeStats(posts)}
+
+		for _, name := range []string{"default", "org", "gemtext"} {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				renderer, err := NewRenderer(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var buf bytes.Buffer
+				if err := renderer.Render(&buf, data); err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(buf.String(), tt.want) {
+					t.Errorf("summary should contain %q:\n%s", tt.want, buf.String())
+				}
+				if tt.author != nil && strings.Contains(buf.String(), "My posts") {
+					t.Errorf("digest summary should not claim the posts are mine:\n%s", buf.String())
+				}
+			})
+		}
+	}
+}
@@ -4,18 +4,75 @@ import "time"
 
 // TemplateData represents the complete data structure passed to templates
 type TemplateData struct {
-	StartDate string
-	EndDate   string
-	Posts     []Post
-	Days      []DayGroup // Posts grouped by day
+	StartDate   string
+	EndDate     string
+	Posts       []Post
+	Days        []DayGroup    // Posts grouped by day
+	LinkDomains []DomainGroup // External links grouped by domain
+	Stats       Stats         // Aggregate counts across all posts
+
+	// Profile details, with --include-pinned
+	Profile      *Profile
+	Pinned       []Post        // Pinned posts, in profile order
+	FeaturedTags []FeaturedTag // Hashtags featured on the profile
+
+	// Rendering posts appended to a journal by --watch; templates leave out the title and summary
+	Appending bool `json:"-"`
+
+	// Wrap each post and the summary in <!-- status:ID --> and <!-- /status:ID --> lines,
+	// for --merge; IDs must be unique, so favorites use fav-ID
+	Markers bool `json:"-"`
 }
 
 // DayGroup represents all posts for a specific day, organized by type
 type DayGroup struct {
-	Date            string
-	OwnPosts        []Post
-	BoostedPosts    []Post
-	FavoritedPosts  []Post
+	Date           string
+	OwnPosts       []Post
+	BoostedPosts   []Post
+	FavoritedPosts []Post
+	Stats          Stats // Aggregate counts for this day
+
+	// The day's heading was written with earlier posts, when --watch appends more; templates leave it out
+	Continued bool `json:"-"`
+}
+
+// Stats holds aggregate counts for a set of posts
+type Stats struct {
+	Total     int // All posts of every type
+	OwnPosts  int // My own posts, including replies
+	Replies   int // My own posts that are replies
+	Boosts    int
+	Favorites int
+
+	// Engagement received on my own posts
+	RepliesReceived    int64
+	ReblogsReceived    int64
+	FavouritesReceived int64
+	TotalEngagement    int64 // Sum of replies, reblogs, and favourites received
+
+	PerDay           []DayCount // Number of posts per day
+	MostEngaged      *Post      // Own post with the most engagement, nil if none had any
+	BoostedAuthors   []string   // Distinct accounts I boosted, sorted
+	FavoritedAuthors []string   // Distinct accounts I favorited, sorted
+}
+
+// DayCount is the number of posts on a given day
+type DayCount struct {
+	Date  string
+	Count int
+}
+
+// DomainGroup represents all external links pointing at a single domain
+type DomainGroup struct {
+	Domain string
+	Links  []LinkRef
+}
+
+// LinkRef is a link along with the post it was found in
+type LinkRef struct {
+	Link
+	PostURL string // URL of the post containing the link
+	Date    string // Date of the post (e.g., "2025-11-11")
 }
 
 // Post represents a Mastodon post with all relevant fields for templating
@@ -25,32 +82,108 @@ type Post struct {
 	FormattedTime     string // Full date and time (e.g., "2025-11-11 14:30")
 	FormattedDate     string // Date only (e.g., "2025-11-11")
 	FormattedTimeOnly string // Time only (e.g., "14:30")
+	EditedAt          time.Time
+	FormattedEditedAt string // Date and time of the last edit, empty if never edited
 	URL               string
 	Content           string
 	ContentWarning    string
 	Visibility        string
 	IsReply           bool
 	IsBoost           bool
-	IsFavorited       bool   // This post was favorited by the user (from favourites endpoint)
+	IsFavorited       bool    // This post was favorited by the user (from favourites endpoint)
+	Author            *Author // Who wrote (or boosted) the post, when it isn't the exported account
 	MediaAttachments  []MediaAttachment
+	Card              *Card     // Link preview card, if the post shares a link
+	Poll              *Poll     // Poll results, if the post has a poll
+	Mentions          []Mention // Accounts mentioned in the post
+	Emojis            []Emoji   // Custom emoji used in the post
+	Tags              []string  // Hashtags used in the post, without the leading "#"
+	Links             []Link    // External links in the post content
 	RepliesCount      int64
 	ReblogsCount      int64
 	FavouritesCount   int64
 
+	// All versions of an edited post, oldest first, ending with the current one (with --include-edit-history)
+	Revisions []Revision
+
 	// For boosted posts
-	BoostCommentary   string // User's commentary when boosting
-	OriginalPost      *OriginalPost // Details of the original boosted/favorited post
+	BoostCommentary string        // User's commentary when boosting
+	OriginalPost    *OriginalPost // Details of the original boosted/favorited post
+}
+
+// Author is the account that wrote a post
+type Author struct {
+	Name     string // Display name
+	Username string
+	Acct     string // Full account name (e.g., "alice@example.social")
+	URL      string
+	Avatar   string
+}
+
+// Profile is an account's public profile
+// JSON tags define the profile export format
+type Profile struct {
+	Name               string         `json:"display_name"`
+	Username           string         `json:"username"`
+	Acct               string         `json:"acct"`
+	URL                string         `json:"url"`
+	Avatar             string         `json:"avatar"`
+	AvatarFile         string         `json:"avatar_file,omitempty"` // Downloaded copy, relative to the output
+	Header             string         `json:"header"`
+	HeaderFile         string         `json:"header_file,omitempty"` // Downloaded copy, relative to the output
+	Bio                string         `json:"bio"`                   // Converted from HTML
+	Fields             []ProfileField `json:"fields"`
+	Emojis             []Emoji        `json:"emojis"` // Custom emoji used in the name, bio, and fields
+	FollowersCount     int64          `json:"followers_count"`
+	FollowingCount     int64          `json:"following_count"`
+	StatusesCount      int64          `json:"statuses_count"`
+	CreatedAt          time.Time      `json:"created_at"`
+	FormattedCreatedAt string         `json:"-"` // Date only (e.g., "2025-11-11")
+	Bot                bool           `json:"bot"`
+	Locked             bool           `json:"locked"`
+}
+
+// ProfileField is a metadata field shown on a profile
+type ProfileField struct {
+	Name       string     `json:"name"`
+	Value      string     `json:"value"`    // Converted from HTML
+	Verified   bool       `json:"verified"` // The linked page links back to the profile
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// FeaturedTag is a hashtag featured on a profile
+type FeaturedTag struct {
+	Name          string // Without the leading "#"
+	URL           string
+	StatusesCount int64
+	LastStatusAt  string // Date of the latest post with the tag, empty if none
 }
 
 // OriginalPost represents the original post that was boosted or favorited
 type OriginalPost struct {
-	AuthorName        string
-	AuthorUsername    string
-	AuthorURL         string
-	Content           string
-	ContentWarning    string
-	URL               string
-	MediaAttachments  []MediaAttachment
+	AuthorName       string
+	AuthorUsername   string
+	AuthorAcct       string // Full account name (e.g., "alice@example.social")
+	AuthorURL        string
+	Content          string
+	ContentWarning   string
+	URL              string
+	MediaAttachments []MediaAttachment
+	Card             *Card
+	Poll             *Poll
+	Mentions         []Mention
+	Links            []Link
+	Emojis           []Emoji // Custom emoji used in the post and the author's display name
+	Tags             []string
+}
+
+// Revision represents one version of an edited post
+type Revision struct {
+	CreatedAt        time.Time
+	FormattedTime    string // When this version was published (e.g., "2025-11-11 14:30")
+	Content          string
+	ContentWarning   string
+	MediaAttachments []MediaAttachment
 }
 
 // MediaAttachment represents a media file attached to a post
@@ -60,3 +193,54 @@ type MediaAttachment struct {
 	PreviewURL  string
 	Description string
 }
+
+// Card represents a link preview generated by the server for a shared link
+type Card struct {
+	URL          string
+	Title        string
+	Description  string // Collapsed to a single line
+	Image        string // Preview image URL
+	Type         string // "link", "photo", "video", or "rich"
+	ProviderName string // Site name (e.g., "The Example Times")
+	AuthorName   string
+}
+
+// Poll represents a poll attached to a post, along with its results
+type Poll struct {
+	Options            []PollOption
+	Multiple           bool  // Voters could choose more than one option
+	VotesCount         int64 // Total votes cast across all options
+	VotersCount        int64 // Number of distinct accounts that voted
+	Closed             bool  // Voting has ended
+	ExpiresAt          time.Time
+	FormattedExpiresAt string // Closing date and time (e.g., "2025-11-11 14:30"), empty if open-ended
+}
+
+// PollOption represents a single poll choice and its share of the vote
+type PollOption struct {
+	Title      string
+	VotesCount int64
+	Percentage float64 // Share of voters choosing this option, from 0 to 100
+}
+
+// Mention represents an account mentioned in a post
+type Mention struct {
+	Username string // Local username (e.g., "alice")
+	Acct     string // Full account name (e.g., "alice@example.social")
+	URL      string // Profile URL
+}
+
+// Emoji represents a custom emoji referenced by shortcode (e.g., ":blobcat:")
+type Emoji struct {
+	Shortcode string `json:"shortcode"` // Without surrounding colons
+	URL       string `json:"url"`
+	StaticURL string `json:"static_url"` // Non-animated version
+}
+
+// Link represents an outbound link found in post content
+// Mention and hashtag links are not included
+type Link struct {
+	Href   string
+	Text   string // Visible link text
+	Domain string // Host name without a leading "www."
+}
@@ -0,0 +1,257 @@
+// Package wxr writes posts as a WordPress eXtended RSS (WXR) import file
+package wxr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+// Site describes the export as a whole
+type Site struct {
+	Title       string
+	Link        string // Usually the account URL
+	Description string
+	PubDate     time.Time // When the export was made
+	AuthorLogin string    // WordPress user the posts are assigned to, can be remapped on import
+	AuthorName  string
+}
+
+// titleLength is the maximum length of titles derived from post content
+const titleLength = 60
+
+// WordPress date formats, in site local time and in GMT
+const (
+	postDateFormat = "2006-01-02 15:04:05"
+	pubDateFormat  = time.RFC1123Z
+)
+
+// Write writes my own posts as WXR 1.2, one post item per post and an
+// attachment item per media attachment
+// Boosts and favorites are skipped, since they were written by other people
+// Public and unlisted posts are published, private and direct ones are private
+func Write(w io.Writer, site Site, posts []templates.Post) error {
+	if site.AuthorLogin == "" {
+		site.AuthorLogin = "mastodon"
+	}
+
+	channel := channel{
+		Title:       site.Title,
+		Link:        site.Link,
+		Description: site.Description,
+		PubDate:     site.PubDate.UTC().Format(pubDateFormat),
+		Language:    "en",
+		WXRVersion:  "1.2",
+		BaseSiteURL: site.Link,
+		BaseBlogURL: site.Link,
+		Author: author{
+			ID:          1,
+			Login:       cdata{site.AuthorLogin},
+			DisplayName: cdata{site.AuthorName},
+		},
+	}
+
+	id := 0
+	for _, post := range posts {
+		if post.IsBoost || post.IsFavorited || post.Author != nil {
+			continue
+		}
+
+		id++
+		parentID := id
+		content := postHTML(post)
+
+		it := newItem(id, post.CreatedAt, site.AuthorLogin)
+		it.Title = postTitle(post)
+		it.Link = post.URL
+		it.GUID = guid{IsPermaLink: "false", Value: post.URL}
+		it.Content = cdata{content}
+		it.Name = "mastodon-" + post.ID
+		it.Status = postStatus(post.Visibility)
+		it.Type = "post"
+		it.Categories = append(it.Categories, category{Domain: "post_format", Nicename: "post-format-status", Value: "Status"})
+		for _, tag := range post.Tags {
+			it.Categories = append(it.Categories, category{Domain: "category", Nicename: strings.ToLower(tag), Value: tag})
+		}
+		it.Meta = []meta{{Key: "mastodon_url", Value: cdata{post.URL}}}
+		channel.Items = append(channel.Items, it)
+
+		for _, media := range post.MediaAttachments {
+			id++
+			attachment := newItem(id, post.CreatedAt, site.AuthorLogin)
+			attachment.Title = path.Base(media.URL)
+			attachment.Link = media.URL
+			attachment.GUID = guid{IsPermaLink: "false", Value: media.URL}
+			attachment.Excerpt = cdata{media.Description} // The caption
+			attachment.Name = fmt.Sprintf("mastodon-%s-%d", post.ID, id)
+			attachment.Status = "inherit"
+			attachment.Parent = parentID
+			attachment.Type = "attachment"
+			attachment.AttachmentURL = media.URL
+			if media.Description != "" {
+				attachment.Meta = []meta{{Key: "_wp_attachment_image_alt", Value: cdata{media.Description}}}
+			}
+			channel.Items = append(channel.Items, attachment)
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write WXR: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(rss{
+		Version:      "2.0",
+		XMLNSExcerpt: "http://wordpress.org/export/1.2/excerpt/",
+		XMLNSContent: "http://purl.org/rss/1.0/modules/content/",
+		XMLNSWfw:     "http://wellformedweb.org/CommentAPI/",
+		XMLNSDC:      "http://purl.org/dc/elements/1.1/",
+		XMLNSWP:      "http://wordpress.org/export/1.2/",
+		Channel:      channel,
+	}); err != nil {
+		return fmt.Errorf("failed to write WXR: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write WXR: %w", err)
+	}
+	return nil
+}
+
+// newItem returns an item with the fields shared by posts and attachments
+func newItem(id int, created time.Time, authorLogin string) item {
+	return item{
+		PubDate:       created.UTC().Format(pubDateFormat),
+		Creator:       cdata{authorLogin},
+		ID:            id,
+		PostDate:      cdata{created.Format(postDateFormat)},
+		PostDateGMT:   cdata{created.UTC().Format(postDateFormat)},
+		CommentStatus: "closed",
+		PingStatus:    "closed",
+	}
+}
+
+// postHTML converts a post's content to HTML, with the content warning first
+// and images inline, so the importer can point them at the imported copies
+func postHTML(post templates.Post) string {
+	var buf bytes.Buffer
+	if post.ContentWarning != "" {
+		fmt.Fprintf(&buf, "<p><strong>CW: %s</strong></p>\n", html.EscapeString(post.ContentWarning))
+	}
+	buf.WriteString(templates.TextHTML(post.Content, post.Links, post.Emojis))
+	for _, media := range post.MediaAttachments {
+		if media.Type == "image" {
+			fmt.Fprintf(&buf, "<p><img src=\"%s\" alt=\"%s\" /></p>\n", html.EscapeString(media.URL), html.EscapeString(media.Description))
+		} else {
+			fmt.Fprintf(&buf, "<p><a href=\"%s\">%s</a></p>\n", html.EscapeString(media.URL), html.EscapeString(media.Type))
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// postTitle derives a title from the first line of content, since posts have none
+func postTitle(post templates.Post) string {
+	text := post.ContentWarning
+	if text == "" {
+		text, _, _ = strings.Cut(strings.TrimSpace(post.Content), "\n")
+	}
+	if utf8.RuneCountInString(text) <= titleLength {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:titleLength])) + "…"
+}
+
+// postStatus maps a visibility to a WordPress post status
+func postStatus(visibility string) string {
+	switch visibility {
+	case "private", "direct":
+		return "private"
+	default:
+		return "publish"
+	}
+}
+
+// cdata is text written as a CDATA section, as WordPress exports do
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+type rss struct {
+	XMLName      xml.Name `xml:"rss"`
+	Version      string   `xml:"version,attr"`
+	XMLNSExcerpt string   `xml:"xmlns:excerpt,attr"`
+	XMLNSContent string   `xml:"xmlns:content,attr"`
+	XMLNSWfw     string   `xml:"xmlns:wfw,attr"`
+	XMLNSDC      string   `xml:"xmlns:dc,attr"`
+	XMLNSWP      string   `xml:"xmlns:wp,attr"`
+	Channel      channel  `xml:"channel"`
+}
+
+type channel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	Language    string `xml:"language"`
+	WXRVersion  string `xml:"wp:wxr_version"`
+	BaseSiteURL string `xml:"wp:base_site_url"`
+	BaseBlogURL string `xml:"wp:base_blog_url"`
+	Author      author `xml:"wp:author"`
+	Items       []item `xml:"item"`
+}
+
+type author struct {
+	ID          int   `xml:"wp:author_id"`
+	Login       cdata `xml:"wp:author_login"`
+	DisplayName cdata `xml:"wp:author_display_name"`
+}
+
+type item struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	PubDate       string     `xml:"pubDate"`
+	Creator       cdata      `xml:"dc:creator"`
+	GUID          guid       `xml:"guid"`
+	Description   string     `xml:"description"`
+	Content       cdata      `xml:"content:encoded"`
+	Excerpt       cdata      `xml:"excerpt:encoded"`
+	ID            int        `xml:"wp:post_id"`
+	PostDate      cdata      `xml:"wp:post_date"`
+	PostDateGMT   cdata      `xml:"wp:post_date_gmt"`
+	CommentStatus string     `xml:"wp:comment_status"`
+	PingStatus    string     `xml:"wp:ping_status"`
+	Name          string     `xml:"wp:post_name"`
+	Status        string     `xml:"wp:status"`
+	Parent        int        `xml:"wp:post_parent"`
+	MenuOrder     int        `xml:"wp:menu_order"`
+	Type          string     `xml:"wp:post_type"`
+	Password      string     `xml:"wp:post_password"`
+	IsSticky      int        `xml:"wp:is_sticky"`
+	AttachmentURL string     `xml:"wp:attachment_url,omitempty"`
+	Categories    []category `xml:"category"`
+	Meta          []meta     `xml:"wp:postmeta"`
+}
+
+type guid struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type category struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Value    string `xml:",cdata"`
+}
+
+type meta struct {
+	Key   string `xml:"wp:meta_key"`
+	Value cdata  `xml:"wp:meta_value"`
+}
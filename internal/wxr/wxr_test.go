@@ -0,0 +1,22 @@
+package wxr
+
+import (
+	"testing"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+)
+
+func TestPostHTMLKeepsPlainText(t *testing.T) {
+	post := templates.Post{
+		ID:             "1",
+		ContentWarning: "<spoilers>",
+		Content:        "*not emphasis* and _this_\n# not a heading\n\n- not a list & <not a tag>",
+	}
+
+	want := "<p><strong>CW: &lt;spoilers&gt;</strong></p>\n" +
+		"<p>*not emphasis* and _this_<br />\n# not a heading</p>\n" +
+		"<p>- not a list &amp; &lt;not a tag&gt;</p>"
+	if got := postHTML(post); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+// Package export fetches Mastodon activity and renders it as markdown.
+//
+// It is the stable, embeddable API behind the mastodon-to-markdown command:
+// the CLI is a thin wrapper around Fetch, NewTemplateData, and Render, so Go
+// programs can reuse the same fetching, conversion, and templating.
+//
+// A typical program fetches posts for a time range and renders them:
+//
+//	opts := export.FetchOptions{
+//		Server:      "https://mastodon.social",
+//		AccessToken: token,
+//		Start:       time.Now().AddDate(0, 0, -7),
+//		PublicOnly:  true,
+//	}
+//	posts, err := export.Fetch(ctx, opts)
+//	if err != nil {
+//		return err
+//	}
+//	tr := opts.TimeRange()
+//	data := export.NewTemplateData(tr.Start, tr.End, posts)
+//	err = export.Render(os.Stdout, data, "")
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version, exported
+// identifiers will not be removed or changed incompatibly; new fields, options,
+// and functions may be added. Post and the other model types are aliases of the
+// types templates are rendered with, so fields may be added to them but existing
+// fields keep their names and meaning. Everything under internal/ is private to
+// this module and may change at any time.
+package export
@@ -0,0 +1,81 @@
+package export_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	mastodonAPI "github.com/mattn/go-mastodon"
+)
+
+func ExampleConvert() {
+	status := &mastodonAPI.Status{
+		ID:         "101",
+		URL:        "https://example.social/@alice/101",
+		CreatedAt:  time.Date(2025, 11, 3, 14, 30, 0, 0, time.UTC),
+		Content:    `<p>Hello <a href="https://go.dev/">go.dev</a></p>`,
+		Visibility: "public",
+	}
+
+	post := export.Convert(status)
+	fmt.Println(post.Content)
+	fmt.Println(post.Links[0].Domain)
+	// Output:
+	// Hello go.dev
+	// go.dev
+}
+
+func ExampleRender() {
+	posts := []export.Post{
+		export.Convert(&mastodonAPI.Status{
+			ID:        "101",
+			URL:       "https://example.social/@alice/101",
+			CreatedAt: time.Date(2025, 11, 3, 14, 30, 0, 0, time.UTC),
+			Content:   "<p>Hello, world</p>",
+		}),
+	}
+
+	start := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	data := export.NewTemplateData(start, start.AddDate(0, 0, 1), posts)
+
+	tmpl := "{{range .Posts}}- {{.Content}} ({{.URL}})\n{{end}}"
+	f, err := os.CreateTemp("", "example-*.tmpl")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(tmpl); err != nil {
+		log.Fatal(err)
+	}
+	f.Close()
+
+	if err := export.Render(os.Stdout, data, f.Name()); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// - Hello, world (https://example.social/@alice/101)
+}
+
+func ExampleFetch() {
+	opts := export.FetchOptions{
+		Server:         "https://mastodon.social",
+		AccessToken:    os.Getenv("MASTODON_ACCESS_TOKEN"),
+		Start:          time.Now().AddDate(0, 0, -7),
+		PublicOnly:     true,
+		ExcludeReplies: true,
+	}
+
+	posts, err := export.Fetch(context.Background(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tr := opts.TimeRange()
+	data := export.NewTemplateData(tr.Start, tr.End, posts)
+	if err := export.Render(os.Stdout, data, ""); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,320 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lmorchard/mastodon-to-markdown/internal/config"
+	"github.com/lmorchard/mastodon-to-markdown/internal/mastodon"
+	"github.com/lmorchard/mastodon-to-markdown/internal/pipeline"
+	"github.com/lmorchard/mastodon-to-markdown/internal/templates"
+	"github.com/lmorchard/mastodon-to-markdown/internal/timerange"
+	mastodonAPI "github.com/mattn/go-mastodon"
+	"github.com/sirupsen/logrus"
+)
+
+// Model types passed to templates
+type (
+	TemplateData    = templates.TemplateData
+	DayGroup        = templates.DayGroup
+	DomainGroup     = templates.DomainGroup
+	LinkRef         = templates.LinkRef
+	Stats           = templates.Stats
+	DayCount        = templates.DayCount
+	Post            = templates.Post
+	OriginalPost    = templates.OriginalPost
+	Revision        = templates.Revision
+	MediaAttachment = templates.MediaAttachment
+	Card            = templates.Card
+	Poll            = templates.Poll
+	PollOption      = templates.PollOption
+	Mention         = templates.Mention
+	Emoji           = templates.Emoji
+	Author          = templates.Author
+	Profile         = templates.Profile
+	ProfileField    = templates.ProfileField
+	FeaturedTag     = templates.FeaturedTag
+	Link            = templates.Link
+)
+
+// TimeRange is a period with start and end times
+type TimeRange = timerange.TimeRange
+
+// Source provides the statuses to export
+// Implement it to fetch from somewhere other than the live Mastodon API
+type Source = pipeline.Source
+
+// HistorySource is implemented by sources that can fetch the edit history of a status
+type HistorySource = pipeline.HistorySource
+
+// ProfileSource is implemented by sources that can fetch pinned posts and featured hashtags
+type ProfileSource = pipeline.ProfileSource
+
+// About is an account's profile, pinned posts, and featured hashtags
+type About = pipeline.About
+
+// Renderer renders template data through a loaded template
+type Renderer = templates.Renderer
+
+// Emoji rendering modes for FetchOptions.EmojiMode
+const (
+	EmojiShortcode = mastodon.EmojiShortcode
+	EmojiImage     = mastodon.EmojiImage
+	EmojiDrop      = mastodon.EmojiDrop
+)
+
+// FetchOptions controls what Fetch retrieves and how posts are filtered
+type FetchOptions struct {
+	// Server and AccessToken are used to connect to the live API when Source is nil
+	// AccessToken is optional when exporting a Hashtag
+	Server      string
+	AccessToken string
+
+	// Account exports another account's statuses, by @user@instance address,
+	// instead of the authenticated account's
+	Account string
+
+	// Hashtag exports the public hashtag timeline instead of an account
+	Hashtag string
+
+	// Timeline exports a timeline of the authenticated user instead of an
+	// account: "home", or "list:" followed by a list's title or ID
+	Timeline string
+
+	// Source overrides the live API, e.g. with a local archive or a test fake
+	Source Source
+
+	// Start and End bound the time range; zero End means now, zero Start means 7 days before End
+	Start time.Time
+	End   time.Time
+
+	ExcludeReplies     bool
+	ExcludeBoosts      bool
+	ExcludeFavorites   bool
+	Visibility         string // Comma-separated visibilities to include, empty for all
+	PublicOnly         bool   // Exclude direct and private posts
+	IncludeEditHistory bool   // Fetch revisions of edited posts
+	MinEngagement      int64  // Drop posts with fewer replies, boosts, and favourites combined
+
+	EmojiMode string // EmojiShortcode (default), EmojiImage, or EmojiDrop
+	SortOrder string // "asc" (oldest first, default) or "desc" (newest first)
+
+	// Log receives progress messages; nil discards them
+	Log logrus.FieldLogger
+}
+
+// TimeRange returns the time range the options select, applying defaults
+func (o FetchOptions) TimeRange() *TimeRange {
+	end := o.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := o.Start
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -7)
+	}
+	return &TimeRange{Start: start, End: end}
+}
+
+// Fetch retrieves posts, boosts, and favorites for the options' time range
+// Without a Source, it connects to Server and exports the Timeline, the
+// Hashtag, the Account, or else the authenticated account
+func Fetch(ctx context.Context, opts FetchOptions) ([]Post, error) {
+	tr := opts.TimeRange()
+	if tr.End.Before(tr.Start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	source, err := OpenSource(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Log != nil {
+		if account, err := source.Account(ctx); err == nil && account != nil {
+			opts.Log.Infof("Fetching posts for @%s", account.Acct)
+		} else if opts.Hashtag != "" {
+			opts.Log.Infof("Fetching posts tagged #%s", strings.TrimPrefix(opts.Hashtag, "#"))
+		}
+	}
+
+	return pipeline.Run(ctx, source, opts.pipelineOptions())
+}
+
+// OpenSource returns opts.Source if set, or else connects to Server for the
+// Timeline, the Hashtag, the Account, or the authenticated account
+// Set the result as opts.Source to reuse one connection for Fetch and FetchAbout
+func OpenSource(ctx context.Context, opts FetchOptions) (Source, error) {
+	if opts.Source != nil {
+		return opts.Source, nil
+	}
+
+	switch {
+	case countSet(opts.Account, opts.Hashtag, opts.Timeline) > 1:
+		return nil, fmt.Errorf("only one of Account, Hashtag, and Timeline may be set")
+	case opts.Timeline != "":
+		return NewTimelineSource(ctx, opts.Server, opts.AccessToken, opts.Timeline)
+	case opts.Hashtag != "":
+		return NewHashtagSource(opts.Server, opts.AccessToken, opts.Hashtag)
+	case opts.Account != "":
+		return NewLookupSource(ctx, opts.Server, opts.AccessToken, opts.Account)
+	default:
+		return NewAccountSource(ctx, opts.Server, opts.AccessToken)
+	}
+}
+
+// FetchAbout retrieves the profile, pinned posts, and featured hashtags of
+// the authenticated account or opts.Account
+// Add them to template data with About.AddTo
+func FetchAbout(ctx context.Context, opts FetchOptions) (*About, error) {
+	source, err := OpenSource(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pipeline.FetchAbout(ctx, source, opts.pipelineOptions())
+}
+
+// pipelineOptions converts the options for the internal pipeline
+func (o FetchOptions) pipelineOptions() pipeline.Options {
+	return pipeline.Options{
+		TimeRange:          o.TimeRange(),
+		ExcludeReplies:     o.ExcludeReplies,
+		ExcludeBoosts:      o.ExcludeBoosts,
+		ExcludeFavorites:   o.ExcludeFavorites,
+		Visibility:         o.Visibility,
+		PublicOnly:         o.PublicOnly,
+		IncludeEditHistory: o.IncludeEditHistory,
+		MinEngagement:      o.MinEngagement,
+		EmojiMode:          o.EmojiMode,
+		SortOrder:          o.SortOrder,
+		Log:                o.Log,
+	}
+}
+
+// NewAccountSource connects to a Mastodon server, verifies the access token,
+// and returns a live API source for the authenticated account
+func NewAccountSource(ctx context.Context, server, accessToken string) (Source, error) {
+	client, err := newClient(server, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := mastodon.NewAccountSource(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify Mastodon credentials: %w", err)
+	}
+	return source, nil
+}
+
+// NewLookupSource connects to a Mastodon server and returns a live API source
+// for another account, by @user@instance address
+// The access token is optional if the server allows unauthenticated lookups
+func NewLookupSource(ctx context.Context, server, accessToken, acct string) (Source, error) {
+	client, err := newPublicClient(server, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return mastodon.NewLookupSource(ctx, client, acct)
+}
+
+// NewHashtagSource returns a live API source for a public hashtag timeline
+// The access token is optional
+func NewHashtagSource(server, accessToken, tag string) (Source, error) {
+	client, err := newPublicClient(server, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return mastodon.NewHashtagSource(client, tag), nil
+}
+
+// NewTimelineSource connects to a Mastodon server and returns a live API
+// source for the authenticated user's "home" timeline or a "list:<title-or-id>"
+func NewTimelineSource(ctx context.Context, server, accessToken, timeline string) (Source, error) {
+	client, err := newClient(server, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case timeline == "home":
+		return mastodon.NewHomeSource(client), nil
+	case strings.HasPrefix(timeline, "list:"):
+		return mastodon.NewListSource(ctx, client, strings.TrimPrefix(timeline, "list:"))
+	default:
+		return nil, fmt.Errorf("unknown timeline %q (expected home or list:<name-or-id>)", timeline)
+	}
+}
+
+// countSet counts the non-empty values
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func newClient(server, accessToken string) (*mastodon.Client, error) {
+	cfg := &config.Config{}
+	cfg.Mastodon.Server = server
+	cfg.Mastodon.AccessToken = accessToken
+
+	client, err := mastodon.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Mastodon client: %w", err)
+	}
+	return client, nil
+}
+
+func newPublicClient(server, accessToken string) (*mastodon.Client, error) {
+	cfg := &config.Config{}
+	cfg.Mastodon.Server = server
+	cfg.Mastodon.AccessToken = accessToken
+
+	client, err := mastodon.NewPublicClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Mastodon client: %w", err)
+	}
+	return client, nil
+}
+
+// Convert converts a Mastodon status (own post, reply, or boost) to a Post
+func Convert(status *mastodonAPI.Status) Post {
+	return mastodon.ConvertStatus(status)
+}
+
+// ConvertFavourite converts a favourited Mastodon status to a Post
+func ConvertFavourite(status *mastodonAPI.Status) Post {
+	return mastodon.ConvertFavourite(status)
+}
+
+// NewTemplateData groups posts by day and domain and computes stats for templates
+func NewTemplateData(start, end time.Time, posts []Post) *TemplateData {
+	return pipeline.NewTemplateData(&TimeRange{Start: start, End: end}, posts)
+}
+
+// NewRenderer loads a template: empty for the default, a built-in name
+// (e.g. "link-roundup"), or the path to a template file
+func NewRenderer(template string) (*Renderer, error) {
+	return templates.NewRenderer(template)
+}
+
+// Render renders data through a template, as accepted by NewRenderer, to w
+func Render(w io.Writer, data *TemplateData, template string) error {
+	renderer, err := NewRenderer(template)
+	if err != nil {
+		return err
+	}
+	return renderer.Render(w, data)
+}
+
+// DefaultTemplate returns the text of the built-in default template
+func DefaultTemplate() string {
+	text, _ := templates.GetDefaultTemplate()
+	return text
+}
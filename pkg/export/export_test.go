@@ -0,0 +1,21 @@
+package export_test
+
+import (
+	"context"
+
+	"github.com/lmorchard/mastodon-to-markdown/pkg/export"
+	mastodonAPI "github.com/mattn/go-mastodon"
+)
+
+// profileSource checks that ProfileSource can be implemented with exported types only
+type profileSource struct{}
+
+func (profileSource) PinnedStatuses(ctx context.Context) ([]*mastodonAPI.Status, error) {
+	return nil, nil
+}
+
+func (profileSource) FeaturedTags(ctx context.Context) ([]export.FeaturedTag, error) {
+	return []export.FeaturedTag{{Name: "golang"}}, nil
+}
+
+var _ export.ProfileSource = profileSource{}